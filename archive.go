@@ -0,0 +1,216 @@
+package order
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LintFromArchive is like Lint but reads the schema from the entry schemaName of a .zip, .tar, .tar.gz or .tgz
+// archive, such as a versioned bundle of schemas, without unpacking it. Relative $refs resolve to other entries
+// of the archive. Violations name the schema like a file inside the archive, e.g. schemas.zip/service.schema.json.
+// Entries that decompress past the size LintURL accepts fail with ErrInputTooLarge
+func LintFromArchive(yamlOrJsonPath, archivePath, schemaName string) error {
+	// Unsupported formats are reported before reading the archive
+	if _, err := parserFor(filepath.Ext(yamlOrJsonPath)); err != nil {
+		return withPath(yamlOrJsonPath, err)
+	}
+
+	archive, err := openArchive(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	if !archive.names[path.Clean(schemaName)] {
+		return fmt.Errorf("%s: no schema %q in archive", archivePath, schemaName)
+	}
+
+	schemaPath := filepath.Join(archivePath, filepath.FromSlash(schemaName))
+	schema, err := parseArchivedSchema(archive, schemaName)
+	if err != nil {
+		return withPath(schemaPath, err)
+	}
+
+	return lintFile(yamlOrJsonPath, schemaPath, schema, LintOptions{})
+}
+
+// schemaArchive indexes the regular files of an archive by their cleaned slash-separated names, reading the
+// content of an entry only once it is requested
+type schemaArchive struct {
+	path  string
+	names map[string]bool
+
+	// contents holds the entries read so far
+	contents map[string][]byte
+
+	// zip and files give random access to the entries of zip archives, nil for tar archives
+	zip   *zip.ReadCloser
+	files map[string]*zip.File
+}
+
+// openArchive indexes the entries of the .zip, .tar, .tar.gz or .tgz archive at archivePath without reading them
+func openArchive(archivePath string) (*schemaArchive, error) {
+	archive := &schemaArchive{path: archivePath, names: make(map[string]bool), contents: make(map[string][]byte)}
+
+	name := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		reader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, err
+		}
+
+		archive.zip = reader
+		archive.files = make(map[string]*zip.File, len(reader.File))
+		for _, file := range reader.File {
+			if file.Mode().IsRegular() {
+				archive.names[path.Clean(file.Name)] = true
+				archive.files[path.Clean(file.Name)] = file
+			}
+		}
+		return archive, nil
+	case strings.HasSuffix(name, ".tar"), strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		err := archive.scanTar(func(name string, _ io.Reader) (bool, error) {
+			archive.names[name] = true
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return archive, nil
+	}
+
+	return nil, fmt.Errorf("%s: unsupported archive format, expected .zip, .tar, .tar.gz or .tgz", archivePath)
+}
+
+// Close releases the archive
+func (a *schemaArchive) Close() error {
+	if a.zip != nil {
+		return a.zip.Close()
+	}
+
+	return nil
+}
+
+// read returns the content of the entry name, reading it from the archive the first time it is requested
+func (a *schemaArchive) read(name string) ([]byte, error) {
+	name = path.Clean(name)
+	if content, ok := a.contents[name]; ok {
+		return content, nil
+	}
+	if !a.names[name] {
+		return nil, fmt.Errorf("no entry %q in archive %s", name, a.path)
+	}
+
+	var content []byte
+	var err error
+	if a.zip != nil {
+		content, err = readZipEntry(a.files[name])
+	} else {
+		// Tar archives can only be read in sequence, so the entry is found by scanning them again
+		err = a.scanTar(func(entry string, r io.Reader) (bool, error) {
+			if entry != name {
+				return true, nil
+			}
+			content, err = readSchemaContent(r)
+			return false, err
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", a.path, name, err)
+	}
+
+	a.contents[name] = content
+	return content, nil
+}
+
+// scanTar calls visit with the cleaned name and content of every regular file of the tar archive until visit
+// returns false. Entries visit doesn't read are skipped without being read
+func (a *schemaArchive) scanTar(visit func(name string, r io.Reader) (bool, error)) error {
+	file, err := os.Open(a.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if !strings.HasSuffix(strings.ToLower(a.path), ".tar") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return withPath(a.path, err)
+		}
+		r = gz
+	}
+
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return withPath(a.path, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		more, err := visit(path.Clean(header.Name), reader)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
+
+// readZipEntry reads the content of file, failing with ErrInputTooLarge past maxSchemaBytes
+func readZipEntry(file *zip.File) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return readSchemaContent(r)
+}
+
+// parseArchivedSchema parses the schema held by the entry schemaName of archive, whose entries references to other
+// files are resolved against. Only the entries the schema reaches are read
+func parseArchivedSchema(archive *schemaArchive, schemaName string) (*SchemaProperty, error) {
+	archivePath := archive.path
+	absArchive, err := filepath.Abs(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	loadEntry := func(name string) (*yaml.Node, error) {
+		content, err := archive.read(name)
+		if err != nil {
+			return nil, err
+		}
+		return parseSchemaFile(content, path.Ext(name))
+	}
+
+	root, err := loadEntry(schemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSchemaDocumentWith(root, filepath.Join(absArchive, filepath.FromSlash(schemaName)), "",
+		func(file string) (*yaml.Node, error) {
+			name, err := filepath.Rel(absArchive, file)
+			if err != nil || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+				return nil, fmt.Errorf("%s is outside archive %s", file, archivePath)
+			}
+			return loadEntry(filepath.ToSlash(name))
+		})
+}
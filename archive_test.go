@@ -0,0 +1,171 @@
+package order
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// archiveEntries are the schemas bundled by the archives of TestLintFromArchive
+var archiveEntries = []struct{ name, content string }{
+	{"v1/service.schema.json", `{"properties": {"name": {}, "spec": {"$ref": "common.json#/definitions/Spec"}}}`},
+	{"v1/common.json", `{"definitions": {"Spec": {"properties": {"replicas": {}, "image": {}}}}}`},
+	{"v1/outside.schema.json", `{"properties": {"spec": {"$ref": "../../common.json"}}}`},
+}
+
+// writeZipArchive writes archiveEntries to a zip archive named name in dir
+func writeZipArchive(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	archivePath := filepath.Join(dir, name)
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("Failed to create test archive: %v", err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for _, entry := range archiveEntries {
+		w, err := writer.Create(entry.name)
+		if err != nil {
+			t.Fatalf("Failed to add archive entry: %v", err)
+		}
+		if _, err := io.WriteString(w, entry.content); err != nil {
+			t.Fatalf("Failed to write archive entry: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close test archive: %v", err)
+	}
+
+	return archivePath
+}
+
+// writeTarArchive writes archiveEntries to a tar archive named name in dir, gzipped when compress is set
+func writeTarArchive(t *testing.T, dir, name string, compress bool) string {
+	t.Helper()
+
+	archivePath := filepath.Join(dir, name)
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("Failed to create test archive: %v", err)
+	}
+	defer file.Close()
+
+	var w io.Writer = file
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(file)
+		w = gz
+	}
+
+	writer := tar.NewWriter(w)
+	for _, entry := range archiveEntries {
+		header := &tar.Header{Name: "./" + entry.name, Mode: 0644, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to add archive entry: %v", err)
+		}
+		if _, err := io.WriteString(writer, entry.content); err != nil {
+			t.Fatalf("Failed to write archive entry: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close test archive: %v", err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatalf("Failed to close test archive: %v", err)
+		}
+	}
+
+	return archivePath
+}
+
+func TestLintFromArchive(t *testing.T) {
+	tempDir := t.TempDir()
+
+	validPath := writeTestFile(t, tempDir, "valid.yaml", "name: web\nspec:\n  replicas: 2\n  image: nginx\n")
+	invalidPath := writeTestFile(t, tempDir, "invalid.yaml", "name: web\nspec:\n  image: nginx\n  replicas: 2\n")
+
+	archives := []string{
+		writeZipArchive(t, tempDir, "schemas.zip"),
+		writeTarArchive(t, tempDir, "schemas.tar", false),
+		writeTarArchive(t, tempDir, "schemas.tgz", true),
+		writeTarArchive(t, tempDir, "schemas.tar.gz", true),
+	}
+
+	for _, archivePath := range archives {
+		t.Run(filepath.Base(archivePath), func(t *testing.T) {
+			if err := LintFromArchive(validPath, archivePath, "v1/service.schema.json"); err != nil {
+				t.Errorf("LintFromArchive() returned an error for a document in order: %v", err)
+			}
+
+			err := LintFromArchive(invalidPath, archivePath, "v1/service.schema.json")
+			var violation *Violation
+			if !errors.As(err, &violation) || violation.Key != "image" || violation.Line != 3 {
+				t.Fatalf("LintFromArchive() returned unexpected error for a document out of order: %v", err)
+			}
+			if expected := filepath.Join(archivePath, "v1", "service.schema.json"); violation.Schema != expected {
+				t.Errorf("LintFromArchive() named the schema %q, expected %q", violation.Schema, expected)
+			}
+
+			err = LintFromArchive(validPath, archivePath, "v2/service.schema.json")
+			if err == nil || err.Error() != archivePath+`: no schema "v2/service.schema.json" in archive` {
+				t.Errorf("LintFromArchive() returned unexpected error for a missing entry: %v", err)
+			}
+
+			err = LintFromArchive(validPath, archivePath, "v1/outside.schema.json")
+			if err == nil || !strings.Contains(err.Error(), "is outside archive") {
+				t.Errorf("LintFromArchive() returned unexpected error for a $ref leaving the archive: %v", err)
+			}
+
+			// Only the schema and the entries its $refs reach are read
+			archive, err := openArchive(archivePath)
+			if err != nil {
+				t.Fatalf("openArchive() returned an error: %v", err)
+			}
+			defer archive.Close()
+
+			if len(archive.names) != len(archiveEntries) {
+				t.Errorf("openArchive() indexed %d entries, expected %d", len(archive.names), len(archiveEntries))
+			}
+			if _, err := parseArchivedSchema(archive, "v1/service.schema.json"); err != nil {
+				t.Fatalf("parseArchivedSchema() returned an error: %v", err)
+			}
+
+			var read []string
+			for name := range archive.contents {
+				read = append(read, name)
+			}
+			sort.Strings(read)
+			if expected := []string{"v1/common.json", "v1/service.schema.json"}; !reflect.DeepEqual(read, expected) {
+				t.Errorf("parseArchivedSchema() read the entries %q, expected %q", read, expected)
+			}
+
+			// Entries are read up to maxSchemaBytes, so archives can't inflate without bound
+			defer func(limit int64) { maxSchemaBytes = limit }(maxSchemaBytes)
+			maxSchemaBytes = 16
+
+			err = LintFromArchive(validPath, archivePath, "v1/service.schema.json")
+			if !errors.Is(err, ErrInputTooLarge) {
+				t.Errorf("LintFromArchive() returned unexpected error for an oversized entry: %v", err)
+			}
+		})
+	}
+
+	t.Run("Unsupported archive", func(t *testing.T) {
+		archivePath := writeTestFile(t, tempDir, "schemas.rar", "")
+		err := LintFromArchive(validPath, archivePath, "v1/service.schema.json")
+		if err == nil || !strings.Contains(err.Error(), "unsupported archive format") {
+			t.Errorf("LintFromArchive() returned unexpected error for an unsupported archive: %v", err)
+		}
+	})
+}
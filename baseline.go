@@ -0,0 +1,118 @@
+package order
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Baseline records known violations, so a repository can adopt ordering enforcement and only fail on
+// violations introduced later. Violations are identified by their file, path, key and message, which names
+// the key they are misplaced against, leaving out positions so edits elsewhere in the file keep them baselined
+type Baseline struct {
+	violations map[baselineEntry]bool
+}
+
+// baselineEntry is the identity of a violation stored in a baseline file
+type baselineEntry struct {
+	File    string `json:"file"`
+	Path    string `json:"path,omitempty"`
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// baselineFile is the content of a baseline file
+type baselineFile struct {
+	Violations []baselineEntry `json:"violations"`
+}
+
+// newBaselineEntry returns the identity of a violation found in the file of a report
+func newBaselineEntry(report Report, violation Violation) baselineEntry {
+	file := violation.File
+	if file == "" {
+		file = report.File
+	}
+
+	return baselineEntry{File: file, Path: strings.Join(violation.Path, "."), Key: violation.Key, Message: violation.Message}
+}
+
+// SaveBaseline writes every violation of reports, such as those returned by LintFiles, to a baseline file at path.
+// Entries are sorted so the file diffs cleanly when it is regenerated
+func SaveBaseline(path string, reports []Report) error {
+	seen := make(map[baselineEntry]bool)
+	file := baselineFile{Violations: []baselineEntry{}}
+	for _, report := range reports {
+		for _, violation := range report.Violations {
+			entry := newBaselineEntry(report, violation)
+			if !seen[entry] {
+				seen[entry] = true
+				file.Violations = append(file.Violations, entry)
+			}
+		}
+	}
+
+	sort.Slice(file.Violations, func(i, j int) bool {
+		a, b := file.Violations[i], file.Violations[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Message < b.Message
+	})
+
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// LoadBaseline reads the baseline file written by SaveBaseline at path
+func LoadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file baselineFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, withPath(path, err)
+	}
+
+	baseline := &Baseline{violations: make(map[baselineEntry]bool, len(file.Violations))}
+	for _, entry := range file.Violations {
+		baseline.violations[entry] = true
+	}
+
+	return baseline, nil
+}
+
+// Subtract returns reports without the violations recorded in the baseline, leaving only new violations.
+// The reports given aren't modified. A nil baseline returns reports unchanged
+func (b *Baseline) Subtract(reports []Report) []Report {
+	if b == nil {
+		return reports
+	}
+
+	subtracted := make([]Report, len(reports))
+	for i, report := range reports {
+		var violations []Violation
+		for _, violation := range report.Violations {
+			if !b.violations[newBaselineEntry(report, violation)] {
+				violations = append(violations, violation)
+			}
+		}
+
+		report.Violations = violations
+		subtracted[i] = report
+	}
+
+	return subtracted
+}
@@ -0,0 +1,72 @@
+package order
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseline(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"name": {}, "version": {}, "spec": {"properties": {"replicas": {}, "image": {}}}}}`)
+	legacyPath := writeTestFile(t, tempDir, "legacy.yaml", "version: 1\nname: web\nspec:\n  image: nginx\n  replicas: 2\n")
+	baselinePath := filepath.Join(tempDir, "baseline.json")
+
+	reports, err := LintFiles([]string{legacyPath}, schemaPath)
+	if err != nil {
+		t.Fatalf("LintFiles() returned an error: %v", err)
+	}
+	if len(reports[0].Violations) != 2 {
+		t.Fatalf("LintFiles() returned %d violations, expected 2", len(reports[0].Violations))
+	}
+	if err := SaveBaseline(baselinePath, reports); err != nil {
+		t.Fatalf("SaveBaseline() returned an error: %v", err)
+	}
+
+	baseline, err := LoadBaseline(baselinePath)
+	if err != nil {
+		t.Fatalf("LoadBaseline() returned an error: %v", err)
+	}
+
+	t.Run("Baselined violations", func(t *testing.T) {
+		// Lines added above the violations don't change their identity
+		writeTestFile(t, tempDir, "legacy.yaml", "# Legacy service\nversion: 1\nname: web\nspec:\n  image: nginx\n  replicas: 2\n")
+
+		reports, err := LintFiles([]string{legacyPath}, schemaPath)
+		if err != nil {
+			t.Fatalf("LintFiles() returned an error: %v", err)
+		}
+		if remaining := baseline.Subtract(reports)[0].Violations; len(remaining) != 0 {
+			t.Errorf("Subtract() kept baselined violations: %v", remaining)
+		}
+		if len(reports[0].Violations) != 2 {
+			t.Errorf("Subtract() modified the reports given")
+		}
+	})
+
+	t.Run("New violations", func(t *testing.T) {
+		newPath := writeTestFile(t, tempDir, "new.yaml", "version: 1\nname: web\n")
+		writeTestFile(t, tempDir, "legacy.yaml", "spec:\n  image: nginx\n  replicas: 2\nversion: 1\nname: web\n")
+
+		reports, err := LintFiles([]string{legacyPath, newPath}, schemaPath)
+		if err != nil {
+			t.Fatalf("LintFiles() returned an error: %v", err)
+		}
+
+		subtracted := baseline.Subtract(reports)
+		legacy, added := subtracted[0].Violations, subtracted[1].Violations
+		if len(legacy) != 1 || legacy[0].Key != "spec" {
+			t.Errorf("Subtract() returned unexpected violations for a file with a new violation: %v", legacy)
+		}
+		if len(added) != 1 || added[0].Key != "version" {
+			t.Errorf("Subtract() returned unexpected violations for a file missing from the baseline: %v", added)
+		}
+	})
+
+	t.Run("Nil baseline", func(t *testing.T) {
+		var nilBaseline *Baseline
+		if got := nilBaseline.Subtract(reports); len(got[0].Violations) != 2 {
+			t.Errorf("Subtract() removed violations without a baseline: %v", got[0].Violations)
+		}
+	})
+}
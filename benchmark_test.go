@@ -0,0 +1,121 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Baseline on an Intel Xeon, from go test -run '^$' -bench 'Validate|ParseSchema' -benchmem:
+//
+//	BenchmarkValidateDeep    144697 ns/op     59664 B/op     708 allocs/op
+//	BenchmarkValidateWide   2856593 ns/op   1090064 B/op    4841 allocs/op
+//	BenchmarkParseSchema   10434267 ns/op  12857218 B/op   93320 allocs/op
+//
+// A refactor making any of them markedly slower, or allocating more, deserves a second look
+
+// deepFixture returns a schema nesting levels objects of width properties, the last property of each level
+// holding the next one, along with a document following it
+func deepFixture(levels, width int) (string, string) {
+	var schema, document strings.Builder
+	for level := 0; level < levels; level++ {
+		schema.WriteString(`{"properties": {`)
+		for i := 0; i < width; i++ {
+			if i > 0 {
+				schema.WriteString(", ")
+			}
+			fmt.Fprintf(&schema, `"key%d": `, i)
+			indent := strings.Repeat("  ", level)
+			if i < width-1 || level == levels-1 {
+				schema.WriteString("{}")
+				fmt.Fprintf(&document, "%skey%d: %d\n", indent, i, i)
+			} else {
+				fmt.Fprintf(&document, "%skey%d:\n", indent, i)
+			}
+		}
+	}
+	for level := 0; level < levels; level++ {
+		schema.WriteString("}}")
+	}
+
+	return schema.String(), document.String()
+}
+
+// wideFixture returns a schema listing width top-level properties, each an object of nested properties,
+// along with a document following it
+func wideFixture(width, nested int) (string, string) {
+	var schema, document strings.Builder
+	schema.WriteString(`{"properties": {`)
+	for i := 0; i < width; i++ {
+		if i > 0 {
+			schema.WriteString(", ")
+		}
+		fmt.Fprintf(&schema, `"key%d": {"type": "object", "properties": {`, i)
+		fmt.Fprintf(&document, "key%d:\n", i)
+		for j := 0; j < nested; j++ {
+			if j > 0 {
+				schema.WriteString(", ")
+			}
+			fmt.Fprintf(&schema, `"nested%d": {"type": "integer"}`, j)
+			fmt.Fprintf(&document, "  nested%d: %d\n", j, j)
+		}
+		schema.WriteString("}}")
+	}
+	schema.WriteString("}}")
+
+	return schema.String(), document.String()
+}
+
+// benchmarkValidate measures validateNodeAgainstSchema on a document already parsed and following the schema
+func benchmarkValidate(b *testing.B, schemaContent, documentContent string) {
+	schema, err := parseJSONSchemaRoot(strings.NewReader(schemaContent))
+	if err != nil {
+		b.Fatalf("parseJSONSchemaRoot() returned an error: %v", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(documentContent), &root); err != nil {
+		b.Fatalf("Failed to parse benchmark document: %v", err)
+	}
+
+	v := &validator{visit: func(violation *Violation) bool {
+		b.Fatalf("validateNodeAgainstSchema() reported a violation: %v", violation)
+		return false
+	}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.validateNodeAgainstSchema(root.Content[0], schema, nil, 1)
+	}
+}
+
+func BenchmarkValidateDeep(b *testing.B) {
+	schema, document := deepFixture(100, 5)
+	benchmarkValidate(b, schema, document)
+}
+
+func BenchmarkValidateWide(b *testing.B) {
+	schema, document := wideFixture(200, 20)
+	benchmarkValidate(b, schema, document)
+}
+
+func BenchmarkParseSchema(b *testing.B) {
+	schema, _ := wideFixture(200, 20)
+	content := []byte(schema)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root, err := parseSchemaFile(content, ".json")
+		if err != nil {
+			b.Fatalf("parseSchemaFile() returned an error: %v", err)
+		}
+		if _, err := parseSchemaDocumentAt(root, "schema.json", ""); err != nil {
+			b.Fatalf("parseSchemaDocumentAt() returned an error: %v", err)
+		}
+	}
+}
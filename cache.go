@@ -0,0 +1,138 @@
+package order
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Cache stores lint outcomes keyed by a hash of the document, schema and options.
+// Implementations must be safe for concurrent use
+type Cache interface {
+	// Get returns the outcome stored for key and whether there was one
+	Get(key string) (valid bool, ok bool)
+	// Set stores the outcome for key
+	Set(key string, valid bool)
+}
+
+// MemoryCache is an in-memory Cache
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]bool
+}
+
+// NewMemoryCache creates an empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]bool)}
+}
+
+// Get returns the outcome stored for key and whether there was one
+func (c *MemoryCache) Get(key string) (bool, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	valid, ok := c.entries[key]
+	return valid, ok
+}
+
+// Set stores the outcome for key
+func (c *MemoryCache) Set(key string, valid bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = valid
+}
+
+// cacheable reports whether lint outcomes under opts can be cached. Outcomes depending on functions or on a
+// Collator, which have no value to hash, never are
+func cacheable(opts LintOptions) bool {
+	return opts.Cache != nil && opts.Include == nil && opts.checked == nil && opts.skipped == nil &&
+		opts.Sort.Collator == nil
+}
+
+// cacheOptions holds the options influencing the outcome of linting, in a form whose JSON encoding only depends
+// on their values. Options added to LintOptions that change outcomes must be added here
+type cacheOptions struct {
+	MaxDepth               int
+	MaxBytes               int64
+	CheckConst             bool
+	NumericValueEquality   bool
+	RequireTrailingNewline bool
+	EnforceConsistentCase  bool
+	RequireHeadComments    bool
+	RequireLineComments    bool
+	IgnorePlaceholderKeys  bool
+	CheckMergedKeys        bool
+	DottedKeys             bool
+	KeyOrderRegex          string
+	CaptureGroup           int
+	IgnorePrefixes         []string
+	GraceKeys              []string
+	IgnoreNullValues       bool
+	CanonicalKeys          bool
+	PrefixOnly             bool
+	UnknownKeysSorted      bool
+	UniformArrayKeys       bool
+	Exact                  bool
+	RequireNonEmptyValues  bool
+	RequireFirstKey        string
+	RequiredFirst          bool
+	WarnDeprecated         bool
+	EnforceDepthRange      [2]int
+	RequireOptInKey        string
+	Embedded               []EmbeddedDocument
+	NumericSort            bool
+}
+
+// cacheKey hashes everything that influences the outcome of linting content
+func cacheKey(content []byte, ext string, schema *SchemaProperty, opts LintOptions) string {
+	h := sha256.New()
+	h.Write(content)
+	fmt.Fprintf(h, "\x00%s\x00", ext)
+
+	// The schema tree only holds plain data, so its JSON encoding is a stable representation
+	schemaJSON, _ := json.Marshal(schema)
+	h.Write(schemaJSON)
+
+	key := cacheOptions{
+		MaxDepth:               opts.MaxDepth,
+		MaxBytes:               opts.MaxBytes,
+		CheckConst:             opts.CheckConst,
+		NumericValueEquality:   opts.NumericValueEquality,
+		RequireTrailingNewline: opts.RequireTrailingNewline,
+		EnforceConsistentCase:  opts.EnforceConsistentCase,
+		RequireHeadComments:    opts.RequireHeadComments,
+		RequireLineComments:    opts.RequireLineComments,
+		IgnorePlaceholderKeys:  opts.IgnorePlaceholderKeys,
+		CheckMergedKeys:        opts.CheckMergedKeys,
+		DottedKeys:             opts.DottedKeys,
+		CaptureGroup:           opts.CaptureGroup,
+		IgnorePrefixes:         opts.IgnorePrefixes,
+		GraceKeys:              opts.GraceKeys,
+		IgnoreNullValues:       opts.IgnoreNullValues,
+		CanonicalKeys:          opts.CanonicalKeys,
+		PrefixOnly:             opts.PrefixOnly,
+		UnknownKeysSorted:      opts.UnknownKeysSorted,
+		UniformArrayKeys:       opts.UniformArrayKeys,
+		Exact:                  opts.Exact,
+		RequireNonEmptyValues:  opts.RequireNonEmptyValues,
+		RequireFirstKey:        opts.RequireFirstKey,
+		RequiredFirst:          opts.RequiredFirst,
+		WarnDeprecated:         opts.WarnDeprecated,
+		EnforceDepthRange:      opts.EnforceDepthRange,
+		RequireOptInKey:        opts.RequireOptInKey,
+		Embedded:               opts.Embedded,
+		NumericSort:            opts.Sort.Numeric,
+	}
+	if opts.KeyOrderRegex != nil {
+		key.KeyOrderRegex = opts.KeyOrderRegex.String()
+	}
+
+	optsJSON, _ := json.Marshal(key)
+	h.Write([]byte{0})
+	h.Write(optsJSON)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,145 @@
+package order
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// countingCache wraps MemoryCache to count lookups that found an entry
+type countingCache struct {
+	*MemoryCache
+	hits int
+}
+
+func (c *countingCache) Get(key string) (bool, bool) {
+	valid, ok := c.MemoryCache.Get(key)
+	if ok {
+		c.hits++
+	}
+	return valid, ok
+}
+
+func TestLintCache(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"first": {}, "second": {}}}`)
+	validContent := []byte("first: 1\nsecond: 2\n")
+	invalidContent := []byte("second: 2\nfirst: 1\n")
+
+	t.Run("Valid documents are remembered", func(t *testing.T) {
+		cache := &countingCache{MemoryCache: NewMemoryCache()}
+		opts := LintOptions{Cache: cache}
+
+		for i := 0; i < 2; i++ {
+			err := LintBytes(validContent, ".yaml", schemaPath, opts)
+			if err != nil {
+				t.Fatalf("LintBytes() returned an error for a valid document: %v", err)
+			}
+		}
+
+		if cache.hits != 1 {
+			t.Errorf("LintBytes() hit the cache %d times, expected 1", cache.hits)
+		}
+	})
+
+	t.Run("Invalid documents are validated again", func(t *testing.T) {
+		cache := &countingCache{MemoryCache: NewMemoryCache()}
+		opts := LintOptions{Cache: cache}
+
+		for i := 0; i < 2; i++ {
+			err := LintBytes(invalidContent, ".yaml", schemaPath, opts)
+			if err == nil {
+				t.Fatalf("LintBytes() did not return an error for an invalid document on run %d", i+1)
+			}
+		}
+	})
+
+	t.Run("Cache hit skips validation", func(t *testing.T) {
+		schema, err := loadSchema(schemaPath)
+		if err != nil {
+			t.Fatalf("loadSchema() returned an error: %v", err)
+		}
+
+		cache := NewMemoryCache()
+		opts := LintOptions{Cache: cache}
+		cache.Set(cacheKey(invalidContent, ".yaml", schema, opts), true)
+
+		err = LintBytes(invalidContent, ".yaml", schemaPath, opts)
+		if err != nil {
+			t.Errorf("LintBytes() validated a document the cache marked as valid: %v", err)
+		}
+
+		err = LintBytes(invalidContent, ".yaml", schemaPath, LintOptions{Cache: cache, CheckConst: true})
+		if err == nil {
+			t.Errorf("LintBytes() reused a cache entry stored for different options")
+		}
+	})
+
+	t.Run("Keys depend on option values", func(t *testing.T) {
+		schema, err := loadSchema(schemaPath)
+		if err != nil {
+			t.Fatalf("loadSchema() returned an error: %v", err)
+		}
+
+		key := func(opts LintOptions) string {
+			return cacheKey(validContent, ".yaml", schema, opts)
+		}
+
+		// Equal regular expressions and embedded schemas compiled or loaded separately share a key
+		regexOpts := LintOptions{KeyOrderRegex: regexp.MustCompile(`^v(\d+)$`)}
+		if key(regexOpts) != key(LintOptions{KeyOrderRegex: regexp.MustCompile(`^v(\d+)$`)}) {
+			t.Errorf("cacheKey() differs for equal KeyOrderRegex values")
+		}
+		if key(regexOpts) == key(LintOptions{KeyOrderRegex: regexp.MustCompile(`^r(\d+)$`)}) {
+			t.Errorf("cacheKey() is the same for different KeyOrderRegex values")
+		}
+
+		embedded := func(name string) LintOptions {
+			return LintOptions{Embedded: []EmbeddedDocument{{Path: []string{"data"}, Format: ".yaml", Properties: []*SchemaProperty{{Name: name}}}}}
+		}
+		if key(embedded("a")) != key(embedded("a")) {
+			t.Errorf("cacheKey() differs for equal Embedded values")
+		}
+		if key(embedded("a")) == key(embedded("b")) {
+			t.Errorf("cacheKey() is the same for different Embedded values")
+		}
+	})
+
+	t.Run("Options without a value bypass the cache", func(t *testing.T) {
+		cache := &countingCache{MemoryCache: NewMemoryCache()}
+		opts := LintOptions{Cache: cache, Sort: SortOptions{Collator: collate.New(language.German)}}
+
+		for i := 0; i < 2; i++ {
+			err := LintBytes(validContent, ".yaml", schemaPath, opts)
+			if err != nil {
+				t.Fatalf("LintBytes() returned an error for a valid document: %v", err)
+			}
+		}
+
+		if cache.hits != 0 || len(cache.entries) != 0 {
+			t.Errorf("LintBytes() used the cache with a Collator set")
+		}
+	})
+
+	t.Run("Every option is hashed or excluded", func(t *testing.T) {
+		// Options that don't change whether a document is valid, or that bypass the cache
+		excluded := map[string]bool{
+			"ShowSource": true, "RecordCheckedPaths": true, "checked": true, "skipped": true, "MaxViolations": true,
+			"Include": true, "Cache": true, "SchemaCacheDir": true, "NoCache": true, "BaseDir": true,
+			"SchemaPointer": true, "Sort": true,
+		}
+
+		hashed := reflect.TypeOf(cacheOptions{})
+		options := reflect.TypeOf(LintOptions{})
+		for i := 0; i < options.NumField(); i++ {
+			name := options.Field(i).Name
+			if _, ok := hashed.FieldByName(name); !ok && !excluded[name] {
+				t.Errorf("LintOptions.%s is neither part of cacheOptions nor excluded from cache keys", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+)
+
+// canonicalKey lowercases key and strips its separators, so user_name, user-name, "User Name" and userName
+// all become username
+func canonicalKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// keyName returns the name key is matched against the schema by, its canonical form under LintOptions.CanonicalKeys
+func (v *validator) keyName(key string) string {
+	if v.opts.CanonicalKeys {
+		return canonicalKey(key)
+	}
+
+	return key
+}
+
+// indexProperties is like indexPropertiesByName but keys the properties by the names document keys are matched by
+func (v *validator) indexProperties(properties []*SchemaProperty) map[string]*SchemaProperty {
+	if !v.opts.CanonicalKeys {
+		return indexPropertiesByName(properties)
+	}
+
+	byName := make(map[string]*SchemaProperty, len(properties))
+	for _, prop := range properties {
+		if _, ok := byName[canonicalKey(prop.Name)]; !ok {
+			byName[canonicalKey(prop.Name)] = prop
+		}
+	}
+	return byName
+}
+
+// checkSchemaOptions checks that schema can be validated against under opts, so it fails before any document is read.
+// Under LintOptions.CanonicalKeys, no level of the schema may hold two properties with the same canonical name,
+// and the formats of LintOptions.Embedded need a registered parser
+func checkSchemaOptions(schema *SchemaProperty, opts LintOptions) error {
+	if err := checkEmbeddedFormats(opts.Embedded); err != nil {
+		return err
+	}
+	if !opts.CanonicalKeys {
+		return nil
+	}
+
+	return checkCanonicalNames(schema, "")
+}
+
+// checkCanonicalNames reports two properties of schema, found at path, or of its descendants whose names have the
+// same canonical form. Levels include the items of arrays and the branches of discriminated unions
+func checkCanonicalNames(schema *SchemaProperty, path string) error {
+	at := path
+	if at == "" {
+		at = "the top level"
+	}
+
+	seen := make(map[string]string, len(schema.Properties))
+	for _, prop := range schema.Properties {
+		canonical := canonicalKey(prop.Name)
+		if other, ok := seen[canonical]; ok && other != prop.Name {
+			return fmt.Errorf("properties %q and %q at %s both have the canonical name %q used by CanonicalKeys",
+				other, prop.Name, at, canonical)
+		}
+		seen[canonical] = prop.Name
+
+		child := prop.Name
+		if path != "" {
+			child = path + "." + prop.Name
+		}
+		if err := checkCanonicalNames(prop, child); err != nil {
+			return err
+		}
+		if prop.Items != nil {
+			if err := checkCanonicalNames(prop.Items, child+"[]"); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, branch := range schema.OneOf {
+		if err := checkCanonicalNames(branch, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
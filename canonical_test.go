@@ -0,0 +1,127 @@
+package order
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLintCanonicalKeys(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+  "required": ["userName"],
+  "properties": {
+    "userName": {},
+    "displayName": {},
+    "contactInfo": {"properties": {"emailAddress": {}, "phoneNumber": {}}},
+    "servers": {"items": {"properties": {"hostName": {}, "port": {}}}}
+  }
+}`)
+	opts := LintOptions{CanonicalKeys: true}
+
+	t.Run("Keys in another naming style", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "valid.yaml", `user_name: alice
+display-name: Alice
+Contact Info:
+  EMAIL_ADDRESS: alice@example.com
+  phone-number: "1"
+servers:
+  - host_name: a
+    port: 80
+`)
+		if err := LintWithOptions(documentPath, schemaPath, opts); err != nil {
+			t.Errorf("LintWithOptions() returned an error for canonical keys in order: %v", err)
+		}
+		if err := LintWithOptions(documentPath, schemaPath, LintOptions{CanonicalKeys: true, Exact: true}); err != nil {
+			t.Errorf("LintWithOptions() returned an error for canonical keys under Exact: %v", err)
+		}
+	})
+
+	t.Run("Messages keep keys as written", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "invalid.yaml", `display-name: Alice
+user_name: alice
+contact_info:
+  phone_number: "1"
+  email_address: alice@example.com
+`)
+		violations, err := lintAll(documentPath, schemaPath, opts)
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+
+		expected := []string{
+			"properties out of order: 'display-name' should come after 'user_name' according to the schema",
+			"in property 'contact_info': properties out of order: 'phone_number' should come after 'email_address' according to the schema",
+		}
+		if len(violations) != len(expected) {
+			t.Fatalf("LintAll() returned %d violations, expected %d: %+v", len(violations), len(expected), violations)
+		}
+		for i, violation := range violations {
+			if violation.Error() != expected[i] {
+				t.Errorf("LintAll() returned %q, expected %q", violation.Error(), expected[i])
+			}
+		}
+		if violations[0].Key != "display-name" {
+			t.Errorf("LintAll() returned key %q, expected the key as written", violations[0].Key)
+		}
+	})
+
+	t.Run("Without CanonicalKeys", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "styled.yaml", "display_name: Alice\nuser_name: alice\n")
+		if err := LintWithOptions(documentPath, schemaPath, LintOptions{}); err != nil {
+			t.Errorf("LintWithOptions() returned an error for keys missing from the schema: %v", err)
+		}
+	})
+
+	t.Run("Exact reports schema names", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "exact.yaml", "display_name: Alice\nnick_name: al\n")
+		err := LintWithOptions(documentPath, schemaPath, LintOptions{CanonicalKeys: true, Exact: true})
+		var violation *Violation
+		if !errors.As(err, &violation) {
+			t.Fatalf("LintWithOptions() did not return a violation under Exact: %v", err)
+		}
+		expected := "properties don't exactly match the schema: unexpected 'nick_name'; missing 'userName', 'contactInfo', 'servers'"
+		if violation.Error() != expected {
+			t.Errorf("LintWithOptions() returned %q, expected %q", violation.Error(), expected)
+		}
+	})
+
+	collisions := []struct {
+		name     string
+		schema   string
+		expected string
+	}{
+		{
+			name:     "Colliding top level properties",
+			schema:   `{"properties": {"user_name": {}, "userName": {}}}`,
+			expected: `properties "user_name" and "userName" at the top level both have the canonical name "username"`,
+		},
+		{
+			name:     "Colliding nested properties",
+			schema:   `{"properties": {"spec": {"properties": {"max-size": {}, "Max Size": {}}}}}`,
+			expected: `properties "max-size" and "Max Size" at spec both have the canonical name "maxsize"`,
+		},
+		{
+			name:     "Colliding array item properties",
+			schema:   `{"properties": {"rules": {"items": {"properties": {"on_error": {}, "onError": {}}}}}}`,
+			expected: `properties "on_error" and "onError" at rules[] both have the canonical name "onerror"`,
+		},
+	}
+
+	documentPath := writeTestFile(t, tempDir, "document.yaml", "name: web\n")
+	for _, tt := range collisions {
+		t.Run(tt.name, func(t *testing.T) {
+			collidingPath := writeTestFile(t, tempDir, "colliding.json", tt.schema)
+
+			err := LintWithOptions(documentPath, collidingPath, opts)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) || !strings.HasPrefix(err.Error(), collidingPath) {
+				t.Errorf("LintWithOptions() returned %v, expected an error containing %q", err, tt.expected)
+			}
+
+			if err := LintWithOptions(documentPath, collidingPath, LintOptions{}); err != nil {
+				t.Errorf("LintWithOptions() returned an error for colliding names without CanonicalKeys: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package order
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Key casing styles detected by EnforceConsistentCase
+const (
+	caseSnake          = "snake_case"
+	caseScreamingSnake = "SCREAMING_SNAKE_CASE"
+	caseKebab          = "kebab-case"
+	caseCamel          = "camelCase"
+	casePascal         = "PascalCase"
+)
+
+// keyCase classifies the casing style of key, returning an empty string when the key fits several styles,
+// like a single lowercase word, or none of them
+func keyCase(key string) string {
+	hasUnderscore := strings.Contains(key, "_")
+	hasDash := strings.Contains(key, "-")
+	hasUpper := strings.IndexFunc(key, unicode.IsUpper) >= 0
+	hasLower := strings.IndexFunc(key, unicode.IsLower) >= 0
+
+	switch {
+	case hasUnderscore && hasDash:
+		return ""
+	case hasUnderscore && !hasUpper:
+		return caseSnake
+	case hasUnderscore && !hasLower:
+		return caseScreamingSnake
+	case hasDash && !hasUpper:
+		return caseKebab
+	case hasUnderscore || hasDash || !hasUpper || !hasLower:
+		return ""
+	case unicode.IsLower([]rune(key)[0]):
+		return caseCamel
+	case unicode.IsUpper([]rune(key)[0]):
+		return casePascal
+	}
+
+	return ""
+}
+
+// casedKey is a document key along with the path of the mapping holding it
+type casedKey struct {
+	path    []string
+	keyNode *yaml.Node
+	style   string
+}
+
+// checkConsistentCase reports every key of the document whose casing differs from the style most keys use
+func (v *validator) checkConsistentCase(root *yaml.Node) {
+	var keys []casedKey
+	collectCasedKeys(root, nil, &keys)
+
+	// Find the dominant style, ties going to the style seen first
+	counts := make(map[string]int)
+	dominant := ""
+	for _, key := range keys {
+		counts[key.style]++
+		if counts[key.style] > counts[dominant] {
+			dominant = key.style
+		}
+	}
+
+	for _, key := range keys {
+		if key.style == dominant {
+			continue
+		}
+
+		if !v.report(key.path, key.keyNode,
+			"inconsistent key casing: '"+key.keyNode.Value+"' is "+key.style+
+				" but the document mostly uses "+dominant) {
+			return
+		}
+	}
+}
+
+// collectCasedKeys gathers every key of node and its descendants that has a recognisable casing style
+func collectCasedKeys(node *yaml.Node, path []string, keys *[]casedKey) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			if style := keyCase(keyNode.Value); style != "" {
+				*keys = append(*keys, casedKey{path: path, keyNode: keyNode, style: style})
+			}
+
+			collectCasedKeys(node.Content[i+1], appendPath(path, keyNode.Value), keys)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			collectCasedKeys(item, indexPath(path, i), keys)
+		}
+	}
+}
+
+// appendPath returns a copy of path extended with key, leaving path untouched
+func appendPath(path []string, key string) []string {
+	return append(append([]string(nil), path...), key)
+}
+
+// indexPath returns a copy of path whose last element refers to the i-th item of the sequence it names,
+// such as "rules[3]"
+func indexPath(path []string, i int) []string {
+	index := "[" + strconv.Itoa(i) + "]"
+	if len(path) == 0 {
+		return []string{index}
+	}
+
+	indexed := append([]string(nil), path...)
+	indexed[len(indexed)-1] += index
+
+	return indexed
+}
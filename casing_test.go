@@ -0,0 +1,95 @@
+package order
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyCase(t *testing.T) {
+	tests := map[string]string{
+		"max_retries": caseSnake,
+		"MAX_RETRIES": caseScreamingSnake,
+		"max-retries": caseKebab,
+		"maxRetries":  caseCamel,
+		"MaxRetries":  casePascal,
+		"retries":     "",
+		"max_Retries": "",
+		"max-re_try":  "",
+	}
+
+	for key, expected := range tests {
+		if style := keyCase(key); style != expected {
+			t.Errorf("keyCase(%q) = %q, expected %q", key, style, expected)
+		}
+	}
+}
+
+func TestEnforceConsistentCase(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"name": {}}}`)
+
+	t.Run("Consistent casing", func(t *testing.T) {
+		validPath := writeTestFile(t, tempDir, "valid.yaml", `---
+name: app
+max_retries: 3
+server:
+  listen_address: 0.0.0.0
+  port: 80
+`)
+
+		err := LintWithOptions(validPath, schemaPath, LintOptions{EnforceConsistentCase: true})
+		if err != nil {
+			t.Errorf("LintWithOptions() returned an error for consistent casing: %v", err)
+		}
+	})
+
+	t.Run("Mixed casing", func(t *testing.T) {
+		invalidPath := writeTestFile(t, tempDir, "invalid.yaml", `---
+name: app
+max_retries: 3
+servers:
+  - listen_address: 0.0.0.0
+    readTimeout: 10
+request_timeout: 5
+`)
+
+		violations, err := lintAll(invalidPath, schemaPath, LintOptions{EnforceConsistentCase: true})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+
+		if len(violations) != 1 {
+			t.Fatalf("LintAll() returned %d violations for mixed casing, expected 1: %v", len(violations), violations)
+		}
+		if violations[0].Key != "readTimeout" || strings.Join(violations[0].Path, ".") != "servers[0]" {
+			t.Errorf("LintAll() reported the wrong key for mixed casing: %v", violations[0])
+		}
+		if !strings.Contains(violations[0].Message, "camelCase") || !strings.Contains(violations[0].Message, "snake_case") {
+			t.Errorf("LintAll() returned unexpected message for mixed casing: %v", violations[0].Message)
+		}
+	})
+
+	t.Run("Key without a value", func(t *testing.T) {
+		RegisterParser("dangling", danglingKeyParser{})
+		danglingPath := writeTestFile(t, tempDir, "config.dangling", "")
+
+		err := LintWithOptions(danglingPath, schemaPath, LintOptions{EnforceConsistentCase: true})
+		if err != nil {
+			t.Errorf("LintWithOptions() returned an error for a mapping with a trailing key: %v", err)
+		}
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		mixedPath := writeTestFile(t, tempDir, "mixed.yaml", `---
+name: app
+max_retries: 3
+readTimeout: 10
+`)
+
+		err := Lint(mixedPath, schemaPath)
+		if err != nil {
+			t.Errorf("Lint() checked casing without EnforceConsistentCase: %v", err)
+		}
+	})
+}
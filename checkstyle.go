@@ -0,0 +1,75 @@
+package order
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// checkstyleResult is the root element of a checkstyle XML report
+type checkstyleResult struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+// checkstyleFile lists the errors of one file in a checkstyle XML report
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+// checkstyleError is a single violation in a checkstyle XML report
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// WriteCheckstyle writes reports to w in the checkstyle XML format understood by CI systems such as Jenkins.
+// Every report gets a file element, so files without violations show up as checked
+func WriteCheckstyle(w io.Writer, reports []Report) error {
+	result := checkstyleResult{Version: "4.3"}
+	for _, report := range reports {
+		file := checkstyleFile{Name: report.File}
+		for _, violation := range report.Violations {
+			// Leave source snippets out of the single-line message
+			violation.Snippet = ""
+
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     violation.Line,
+				Column:   violation.Column,
+				Severity: violation.Severity.String(),
+				Message:  violation.Error(),
+				Source:   "order",
+			})
+		}
+
+		// Note violations left out of a truncated report next to the last one kept
+		if report.Truncated > 0 && len(report.Violations) > 0 {
+			last := report.Violations[len(report.Violations)-1]
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     last.Line,
+				Column:   last.Column,
+				Severity: "info",
+				Message:  truncatedMessage(report.Truncated),
+				Source:   "order",
+			})
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
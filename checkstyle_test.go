@@ -0,0 +1,60 @@
+package order
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteCheckstyle(t *testing.T) {
+	reports := []Report{
+		{
+			File: "config.yaml",
+			Violations: []Violation{
+				{
+					Path:    []string{"dependencies"},
+					Key:     "development",
+					Line:    7,
+					Column:  3,
+					Message: "properties out of order: 'development' should come after 'production' according to the schema",
+				},
+				{
+					Key:      "legacy",
+					Line:     9,
+					Column:   1,
+					Message:  "deprecated property 'legacy'",
+					Severity: SeverityWarning,
+					Snippet:  "> 9 | legacy: true",
+				},
+			},
+		},
+		{
+			File:       "legacy.yaml",
+			Violations: []Violation{{Key: "b", Line: 2, Column: 1, Message: "properties out of order: 'b' should come after 'a' according to the schema"}},
+			Truncated:  12,
+		},
+		{File: "valid.yaml"},
+	}
+
+	var b strings.Builder
+	err := WriteCheckstyle(&b, reports)
+	if err != nil {
+		t.Fatalf("WriteCheckstyle() returned an error: %v", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="config.yaml">
+    <error line="7" column="3" severity="error" message="in property &#39;dependencies&#39;: properties out of order: &#39;development&#39; should come after &#39;production&#39; according to the schema" source="order"></error>
+    <error line="9" column="1" severity="warning" message="deprecated property &#39;legacy&#39;" source="order"></error>
+  </file>
+  <file name="legacy.yaml">
+    <error line="2" column="1" severity="error" message="properties out of order: &#39;b&#39; should come after &#39;a&#39; according to the schema" source="order"></error>
+    <error line="2" column="1" severity="info" message="... and 12 more" source="order"></error>
+  </file>
+  <file name="valid.yaml"></file>
+</checkstyle>
+`
+	if b.String() != expected {
+		t.Errorf("WriteCheckstyle() wrote:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+}
@@ -0,0 +1,61 @@
+// Command order reorders YAML and JSON files against the property order of a JSON schema
+//
+//	order fix --schema schema.json --write 'configs/**/*.yaml'
+//
+// Without --write the files are left untouched, and the command exits with status 1 when any of them is out of order
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/roscrl/order"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "fix" {
+		fmt.Fprintln(os.Stderr, "usage: order fix --schema schema.json [--write] [--annotate] pattern")
+		os.Exit(2)
+	}
+
+	os.Exit(fix(os.Args[2:]))
+}
+
+// fix runs the fix command on args, returning the exit status
+func fix(args []string) int {
+	flags := flag.NewFlagSet("fix", flag.ExitOnError)
+	schemaPath := flags.String("schema", "", "JSON schema holding the property order")
+	write := flags.Bool("write", false, "save the reordered files")
+	annotate := flags.Bool("annotate", false, "comment every moved YAML key with its schema position")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: order fix --schema schema.json [--write] [--annotate] pattern")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if *schemaPath == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	reports, err := order.FixGlob(flags.Arg(0), *schemaPath, order.FixOptions{Annotate: *annotate, Write: *write})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "order: %v\n", err)
+		return 1
+	}
+	if err := order.WriteFixSummary(os.Stdout, reports); err != nil {
+		fmt.Fprintf(os.Stderr, "order: %v\n", err)
+		return 1
+	}
+
+	if !*write {
+		for _, report := range reports {
+			if len(report.Moves) > 0 {
+				return 1
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,62 @@
+package order
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkCommentPlacement reports every documented key of node and its descendants whose comment isn't placed
+// as RequireHeadComments or RequireLineComments expects, returning whether validation should continue
+func (v *validator) checkCommentPlacement(node *yaml.Node, path []string) bool {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+			// A comment trailing a scalar value on the line of its key documents the key
+			lineComment := keyNode.LineComment != "" ||
+				valueNode.Kind == yaml.ScalarNode && valueNode.Line == keyNode.Line && valueNode.LineComment != ""
+
+			if v.opts.RequireHeadComments && lineComment {
+				if !v.report(path, keyNode, "comment of '"+keyNode.Value+"' should be placed above it, not trailing it") {
+					return false
+				}
+			}
+			if v.opts.RequireLineComments && keyNode.HeadComment != "" && !v.commentsOutNestedLines(keyNode) {
+				if !v.report(path, keyNode, "comment of '"+keyNode.Value+"' should trail it, not be placed above it") {
+					return false
+				}
+			}
+
+			if !v.checkCommentPlacement(valueNode, appendPath(path, keyNode.Value)) {
+				return false
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if !v.checkCommentPlacement(item, indexPath(path, i)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// commentsOutNestedLines reports whether the comment right above keyNode is indented past it, such as the commented-out
+// children of an empty key before it. yaml.v3 attaches such comments to the next key, but they don't document it
+func (v *validator) commentsOutNestedLines(keyNode *yaml.Node) bool {
+	lines := bytes.Split(v.content, []byte("\n"))
+	for n := keyNode.Line - 1; n >= 1 && n <= len(lines); n-- {
+		line := bytes.TrimRight(lines[n-1], "\r")
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) == 0 {
+			continue
+		}
+
+		return trimmed[0] == '#' && len(line)-len(trimmed) >= keyNode.Column
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package order
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommentPlacement(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"name": {}, "spec": {}}}`)
+	headPath := writeTestFile(t, tempDir, "head.yaml", `---
+# The name of the service
+name: web
+spec:
+  # How many instances run
+  replicas: 2
+  image: nginx
+`)
+	mixedPath := writeTestFile(t, tempDir, "mixed.yaml", `---
+name: web # The name of the service
+spec: # The deployment
+  # How many instances run
+  replicas: 2
+  ports:
+    - name: http # The public port
+`)
+
+	t.Run("Head comments", func(t *testing.T) {
+		err := LintWithOptions(headPath, schemaPath, LintOptions{RequireHeadComments: true})
+		if err != nil {
+			t.Errorf("LintWithOptions() returned an error for keys documented above: %v", err)
+		}
+
+		violations, err := lintAll(mixedPath, schemaPath, LintOptions{RequireHeadComments: true})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+
+		var got []string
+		for _, violation := range violations {
+			got = append(got, strings.Join(append(violation.Path, violation.Key), "."))
+		}
+		expected := []string{"name", "spec", "spec.ports[0].name"}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("LintAll() reported trailing comments on %v, expected %v", got, expected)
+		}
+		if len(violations) > 0 && violations[0].Message != "comment of 'name' should be placed above it, not trailing it" {
+			t.Errorf("LintAll() returned an unexpected message: %s", violations[0].Message)
+		}
+	})
+
+	t.Run("Line comments", func(t *testing.T) {
+		violations, err := lintAll(mixedPath, schemaPath, LintOptions{RequireLineComments: true})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+		if len(violations) != 1 || violations[0].Key != "replicas" || violations[0].Line != 5 {
+			t.Errorf("LintAll() returned unexpected violations for a comment above its key: %+v", violations)
+		}
+	})
+
+	t.Run("Not checked by default", func(t *testing.T) {
+		err := Lint(mixedPath, schemaPath)
+		if err != nil {
+			t.Errorf("Lint() checked comment placement without the options: %v", err)
+		}
+	})
+}
+
+func TestCommentedOutKeys(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json",
+		`{"properties": {"name": {}, "spec": {"properties": {"replicas": {}, "image": {}}}, "status": {}}}`)
+	commentedPath := writeTestFile(t, tempDir, "commented.yaml", `---
+name: web
+# status: {}
+spec:
+  # image: nginx
+  # replicas: 2
+status: {}
+`)
+
+	t.Run("Commented-out keys aren't ordered", func(t *testing.T) {
+		if err := Lint(commentedPath, schemaPath); err != nil {
+			t.Errorf("Lint() returned an error for keys out of order only in comments: %v", err)
+		}
+	})
+
+	t.Run("Comments of an empty value", func(t *testing.T) {
+		violations, err := lintAll(commentedPath, schemaPath, LintOptions{RequireLineComments: true})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+		if len(violations) != 1 || violations[0].Key != "spec" || violations[0].Line != 4 {
+			t.Errorf("LintAll() returned unexpected violations for comments nested under an empty key: %+v", violations)
+		}
+	})
+}
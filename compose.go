@@ -0,0 +1,89 @@
+package order
+
+// MergeSchemaProperties merges the overlay property tree into base by name, returning a new tree and leaving both
+// unchanged. Properties of base keep their order, followed by those only the overlay has in overlay order.
+// Properties both have merge their nested properties and array items the same way, and base wins any other
+// conflict: settings such as Const, Order or Section come from base, the overlay only filling those base leaves unset
+func MergeSchemaProperties(base, overlay []*SchemaProperty) []*SchemaProperty {
+	overlayByName := indexPropertiesByName(overlay)
+	baseByName := indexPropertiesByName(base)
+
+	var merged []*SchemaProperty
+	for _, prop := range base {
+		if other, ok := overlayByName[prop.Name]; ok {
+			merged = append(merged, mergeSchemaProperty(prop, other))
+		} else {
+			merged = append(merged, cloneSchemaProperty(prop))
+		}
+	}
+	for _, prop := range overlay {
+		if _, ok := baseByName[prop.Name]; !ok {
+			merged = append(merged, cloneSchemaProperty(prop))
+		}
+	}
+
+	return merged
+}
+
+// mergeSchemaProperty merges overlay into a copy of base, both describing the same property
+func mergeSchemaProperty(base, overlay *SchemaProperty) *SchemaProperty {
+	merged := cloneSchemaProperty(base)
+	merged.Properties = MergeSchemaProperties(base.Properties, overlay.Properties)
+
+	switch {
+	case base.Items != nil && overlay.Items != nil:
+		merged.Items = mergeSchemaProperty(base.Items, overlay.Items)
+	case overlay.Items != nil:
+		merged.Items = cloneSchemaProperty(overlay.Items)
+	}
+
+	if len(merged.OrderConstraints) == 0 {
+		merged.OrderConstraints = append([][2]string(nil), overlay.OrderConstraints...)
+	}
+	if merged.Const == nil && overlay.Const != nil {
+		value := *overlay.Const
+		merged.Const = &value
+	}
+	if merged.Order == "" {
+		merged.Order = overlay.Order
+	}
+	if merged.Section == "" {
+		merged.Section = overlay.Section
+	}
+	merged.Required = merged.Required || overlay.Required
+	merged.Deprecated = merged.Deprecated || overlay.Deprecated
+	merged.SortItems = merged.SortItems || overlay.SortItems
+	if merged.Discriminator == "" && len(merged.OneOf) == 0 {
+		merged.Discriminator = overlay.Discriminator
+		for _, branch := range overlay.OneOf {
+			merged.OneOf = append(merged.OneOf, cloneSchemaProperty(branch))
+		}
+	}
+
+	return merged
+}
+
+// cloneSchemaProperty returns a deep copy of prop, so merged trees never share properties with their sources
+func cloneSchemaProperty(prop *SchemaProperty) *SchemaProperty {
+	clone := *prop
+
+	clone.Properties = nil
+	for _, child := range prop.Properties {
+		clone.Properties = append(clone.Properties, cloneSchemaProperty(child))
+	}
+	if prop.Items != nil {
+		clone.Items = cloneSchemaProperty(prop.Items)
+	}
+	clone.OneOf = nil
+	for _, branch := range prop.OneOf {
+		clone.OneOf = append(clone.OneOf, cloneSchemaProperty(branch))
+	}
+	if prop.Const != nil {
+		value := *prop.Const
+		clone.Const = &value
+	}
+	clone.OrderConstraints = append([][2]string(nil), prop.OrderConstraints...)
+	clone.DiscriminatorValues = append([]string(nil), prop.DiscriminatorValues...)
+
+	return &clone
+}
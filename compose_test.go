@@ -0,0 +1,73 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeSchemaProperties(t *testing.T) {
+	image := "nginx"
+	base := []*SchemaProperty{
+		{Name: "name", Required: true},
+		{Name: "spec", Properties: []*SchemaProperty{{Name: "replicas"}, {Name: "image", Const: &image}}},
+		{Name: "rules", Items: &SchemaProperty{Properties: []*SchemaProperty{{Name: "when"}}}},
+	}
+	overlay := []*SchemaProperty{
+		{Name: "labels", Order: OrderAlphabetical},
+		{Name: "spec", Section: "runtime", Properties: []*SchemaProperty{{Name: "ports"}, {Name: "image", Deprecated: true}, {Name: "replicas"}}},
+		{Name: "rules", Items: &SchemaProperty{Properties: []*SchemaProperty{{Name: "then"}, {Name: "when"}}}},
+		{Name: "name", Section: "metadata"},
+	}
+
+	merged := MergeSchemaProperties(base, overlay)
+
+	expected := []*SchemaProperty{
+		{Name: "name", Required: true, Section: "metadata"},
+		{Name: "spec", Section: "runtime", Properties: []*SchemaProperty{
+			{Name: "replicas"},
+			{Name: "image", Const: &image, Deprecated: true},
+			{Name: "ports"},
+		}},
+		{Name: "rules", Items: &SchemaProperty{Properties: []*SchemaProperty{{Name: "when"}, {Name: "then"}}}},
+		{Name: "labels", Order: OrderAlphabetical},
+	}
+	if names, expectedNames := propertyPaths(merged, ""), propertyPaths(expected, ""); !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("MergeSchemaProperties() returned properties %v, expected %v", names, expectedNames)
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("MergeSchemaProperties() returned %+v, expected %+v", merged, expected)
+	}
+
+	t.Run("Base wins conflicts", func(t *testing.T) {
+		other := "httpd"
+		merged := MergeSchemaProperties(
+			[]*SchemaProperty{{Name: "image", Const: &image, Section: "a"}},
+			[]*SchemaProperty{{Name: "image", Const: &other, Section: "b"}},
+		)
+		if *merged[0].Const != "nginx" || merged[0].Section != "a" {
+			t.Errorf("MergeSchemaProperties() returned %+v, expected the settings of base", merged[0])
+		}
+	})
+
+	t.Run("Sources are left unchanged", func(t *testing.T) {
+		merged[1].Properties[0].Name = "changed"
+		*merged[1].Properties[1].Const = "changed"
+		if base[1].Properties[0].Name != "replicas" || image != "nginx" || len(base[1].Properties) != 2 {
+			t.Errorf("MergeSchemaProperties() returned a tree sharing properties with base: %+v", base[1].Properties)
+		}
+	})
+}
+
+// propertyPaths lists the dotted paths of properties and their descendants, array items written as []
+func propertyPaths(properties []*SchemaProperty, prefix string) []string {
+	var paths []string
+	for _, prop := range properties {
+		paths = append(paths, prefix+prop.Name)
+		paths = append(paths, propertyPaths(prop.Properties, prefix+prop.Name+".")...)
+		if prop.Items != nil {
+			paths = append(paths, propertyPaths(prop.Items.Properties, prefix+prop.Name+"[].")...)
+		}
+	}
+
+	return paths
+}
@@ -0,0 +1,173 @@
+package order
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LintConsistent validates that the keys bPath shares with aPath appear in the same relative order,
+// using aPath as the reference. Keys present in only one of the files are ignored.
+// The first divergence is reported at its position in bPath
+func LintConsistent(aPath, bPath string) error {
+	_, aRoot, err := parseDocument(aPath, LintOptions{})
+	if err != nil {
+		return withPath(aPath, err)
+	}
+
+	_, bRoot, err := parseDocument(bPath, LintOptions{})
+	if err != nil {
+		return withPath(bPath, err)
+	}
+
+	if len(aRoot.Content) == 0 || len(bRoot.Content) == 0 {
+		return nil
+	}
+
+	// The reference document plays the part of the schema
+	err = compareNodeOrder(aRoot.Content[0], bRoot.Content[0], aPath)
+	setViolationPaths(err, bPath, aPath, "")
+
+	return withPath(bPath, err)
+}
+
+// compareNodeOrder checks that the keys of mapping b common to mapping a follow a's order, recursing into
+// values that are mappings in both
+func compareNodeOrder(a, b *yaml.Node, aPath string) error {
+	if a.Kind != yaml.MappingNode || b.Kind != yaml.MappingNode {
+		return nil // Only mappings have an order to compare
+	}
+
+	aValues := mappingValues(a)
+	bValues := mappingValues(b)
+
+	// Collect the common keys in the order each document lists them
+	var aKeys []string
+	for i := 0; i+1 < len(a.Content); i += 2 {
+		key := a.Content[i].Value
+		if _, ok := bValues[key]; ok && aValues[key] == a.Content[i+1] {
+			aKeys = append(aKeys, key)
+		}
+	}
+
+	var bKeyNodes []*yaml.Node
+	for i := 0; i+1 < len(b.Content); i += 2 {
+		key := b.Content[i].Value
+		if _, ok := aValues[key]; ok && bValues[key] == b.Content[i+1] {
+			bKeyNodes = append(bKeyNodes, b.Content[i])
+		}
+	}
+
+	// The first position where the orders differ is the divergence
+	for i, keyNode := range bKeyNodes {
+		if keyNode.Value != aKeys[i] {
+			return newViolation(keyNode,
+				"properties out of order: '"+keyNode.Value+"' should come after '"+aKeys[i]+
+					"' as in "+aPath)
+		}
+	}
+
+	// Now recursively compare nested mappings
+	for _, keyNode := range bKeyNodes {
+		err := compareNodeOrder(aValues[keyNode.Value], bValues[keyNode.Value], aPath)
+		var violation *Violation
+		if errors.As(err, &violation) {
+			violation.Path = append([]string{keyNode.Value}, violation.Path...)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mappingValues indexes the values of a mapping node by key, keeping the first occurrence of duplicated keys
+func mappingValues(node *yaml.Node) map[string]*yaml.Node {
+	values := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if _, ok := values[node.Content[i].Value]; !ok {
+			values[node.Content[i].Value] = node.Content[i+1]
+		}
+	}
+
+	return values
+}
+
+// LintDirConsistent lints the files directly inside dir whose name matches glob, using filepath.Match syntax,
+// against the key order of the alphabetically first of them. That reference file is named as the Schema
+// of the returned reports, one for each other matching file
+func LintDirConsistent(dir, glob string) ([]Report, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matched, err := filepath.Match(glob, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	// ReadDir sorts entries by name, so the first match is the reference
+	referencePath := paths[0]
+
+	_, referenceRoot, err := parseDocument(referencePath, LintOptions{})
+	if err != nil {
+		return nil, withPath(referencePath, err)
+	}
+	schema := &SchemaProperty{}
+	if len(referenceRoot.Content) > 0 {
+		schema = schemaFromDocument(referenceRoot.Content[0])
+	}
+
+	var reports []Report
+	for _, path := range paths[1:] {
+		violations, err := lintFileAll(path, referencePath, schema, LintOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, Report{File: path, Schema: referencePath, SchemaSource: referencePath, Violations: violations})
+	}
+
+	return reports, nil
+}
+
+// schemaFromDocument builds a schema listing the keys of node in their order, nested mappings giving the order
+// of nested properties
+func schemaFromDocument(node *yaml.Node) *SchemaProperty {
+	schema := &SchemaProperty{}
+	if node.Kind != yaml.MappingNode {
+		return schema
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if seen[keyNode.Value] {
+			continue
+		}
+		seen[keyNode.Value] = true
+
+		prop := schemaFromDocument(valueNode)
+		prop.Name = keyNode.Value
+		schema.Properties = append(schema.Properties, prop)
+	}
+
+	return schema
+}
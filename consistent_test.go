@@ -0,0 +1,126 @@
+package order
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintConsistent(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldPath := writeTestFile(t, tempDir, "old.yaml", `name: app
+version: 1.0.0
+settings:
+  timeout: 30
+  retries: 3
+  debug: false
+legacy: true
+`)
+
+	t.Run("Same order with keys added and removed", func(t *testing.T) {
+		newPath := writeTestFile(t, tempDir, "new.yaml", `name: app
+description: added
+version: 2.0.0
+settings:
+  timeout: 30
+  debug: false
+`)
+
+		err := LintConsistent(oldPath, newPath)
+		if err != nil {
+			t.Errorf("LintConsistent() returned an error for consistent files: %v", err)
+		}
+	})
+
+	t.Run("Top-level divergence", func(t *testing.T) {
+		newPath := writeTestFile(t, tempDir, "reordered.yaml", `version: 2.0.0
+name: app
+`)
+
+		err := LintConsistent(oldPath, newPath)
+		if err == nil {
+			t.Errorf("LintConsistent() did not return an error for a top-level divergence")
+		} else if !strings.Contains(err.Error(), "'version' should come after 'name'") {
+			t.Errorf("LintConsistent() returned unexpected error for a top-level divergence: %v", err)
+		}
+	})
+
+	t.Run("Nested divergence", func(t *testing.T) {
+		newPath := writeTestFile(t, tempDir, "nested.json", `{
+  "name": "app",
+  "settings": {
+    "debug": false,
+    "retries": 3
+  }
+}`)
+
+		err := LintConsistent(oldPath, newPath)
+		var violation *Violation
+		if !errors.As(err, &violation) {
+			t.Fatalf("LintConsistent() did not return a *Violation for a nested divergence: %v", err)
+		}
+		if strings.Join(violation.Path, ".") != "settings" || violation.Key != "debug" || violation.Line != 4 {
+			t.Errorf("LintConsistent() returned unexpected violation for a nested divergence: %+v", violation)
+		}
+	})
+
+	t.Run("Key without a value", func(t *testing.T) {
+		RegisterParser("dangling", danglingKeyParser{})
+		danglingPath := writeTestFile(t, tempDir, "config.dangling", "")
+
+		err := LintConsistent(danglingPath, danglingPath)
+		if err != nil {
+			t.Errorf("LintConsistent() returned an error for a mapping with a trailing key: %v", err)
+		}
+	})
+}
+
+func TestLintDirConsistent(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, "services")
+	if err := os.Mkdir(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+
+	referencePath := writeTestFile(t, configDir, "api.yaml", "name: api\nport: 80\nlimits:\n  cpu: 1\n  memory: 1Gi\n")
+	writeTestFile(t, configDir, "web.yaml", "name: web\nextra: true\nport: 8080\n")
+	writeTestFile(t, configDir, "worker.yaml", "port: 0\nname: worker\nlimits:\n  memory: 2Gi\n  cpu: 2\n")
+	writeTestFile(t, configDir, "notes.txt", "not linted")
+
+	reports, err := LintDirConsistent(configDir, "*.yaml")
+	if err != nil {
+		t.Fatalf("LintDirConsistent() returned an error: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("LintDirConsistent() returned %d reports, expected 2: %+v", len(reports), reports)
+	}
+
+	for _, report := range reports {
+		if report.Schema != referencePath {
+			t.Errorf("LintDirConsistent() used %q as the reference, expected %q", report.Schema, referencePath)
+		}
+	}
+
+	if filepath.Base(reports[0].File) != "web.yaml" || len(reports[0].Violations) != 0 {
+		t.Errorf("LintDirConsistent() returned unexpected report: %+v", reports[0])
+	}
+
+	worker := reports[1]
+	if filepath.Base(worker.File) != "worker.yaml" || len(worker.Violations) != 2 {
+		t.Fatalf("LintDirConsistent() returned unexpected report: %+v", worker)
+	}
+	if worker.Violations[0].Key != "port" || worker.Violations[1].Key != "memory" ||
+		strings.Join(worker.Violations[1].Path, ".") != "limits" {
+		t.Errorf("LintDirConsistent() returned unexpected violations: %+v", worker.Violations)
+	}
+
+	t.Run("No matching files", func(t *testing.T) {
+		reports, err := LintDirConsistent(configDir, "*.json")
+		if err != nil || len(reports) != 0 {
+			t.Errorf("LintDirConsistent() returned %v, %v for a glob matching nothing", reports, err)
+		}
+	})
+}
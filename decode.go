@@ -0,0 +1,135 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OrderedMap is a decoded mapping that remembers the order of its keys.
+// Nested mappings are decoded as *OrderedMap, sequences as []any and scalars as their natural Go type
+type OrderedMap struct {
+	Keys   []string
+	Values map[string]any
+}
+
+// DecodeOrdered decodes a YAML or JSON mapping from r, keeping the order of its keys.
+// format names the parser to use, such as "yaml", "json" or an extension added with RegisterParser
+func DecodeOrdered(r io.Reader, format string) (*OrderedMap, error) {
+	_, root, err := readDocument(r, format, LintOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(root.Content) == 0 || resolveAlias(root.Content[0]).Kind != yaml.MappingNode {
+		return nil, errors.New("expected document to be a mapping")
+	}
+
+	value, err := decodeOrderedNode(root.Content[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*OrderedMap), nil
+}
+
+// decodeOrderedNode converts a node into an *OrderedMap, []any or scalar value
+func decodeOrderedNode(node *yaml.Node) (any, error) {
+	node = resolveAlias(node)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		m := &OrderedMap{Values: make(map[string]any)}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+
+			value, err := decodeOrderedNode(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			// Later duplicates override the value but keep the position of the first occurrence
+			if _, ok := m.Values[key]; !ok {
+				m.Keys = append(m.Keys, key)
+			}
+			m.Values[key] = value
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		items := make([]any, 0, len(node.Content))
+		for _, itemNode := range node.Content {
+			item, err := decodeOrderedNode(itemNode)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	var value any
+	if err := node.Decode(&value); err != nil {
+		// Scalars with custom tags such as !Ref keep their text
+		return node.Value, nil
+	}
+
+	return value, nil
+}
+
+// ValidateOrderedMap validates the key order of m and of the ordered maps nested in it, including inside sequences,
+// against schema without serializing it. m may come from DecodeOrdered or be built in code. Values that are neither
+// *OrderedMap nor []any are encoded like yaml.Marshal encodes them, so Go maps are checked with their keys sorted.
+// Violations have no line or column as the map has no source
+func ValidateOrderedMap(m *OrderedMap, schema []*SchemaProperty) error {
+	node, err := orderedNode(m)
+	if err != nil {
+		return err
+	}
+
+	root := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}
+	return firstViolation(nil, root, &SchemaProperty{Properties: schema}, LintOptions{})
+}
+
+// orderedNode converts a value as decoded by DecodeOrdered into a node, keeping the order of OrderedMap keys
+func orderedNode(value any) (*yaml.Node, error) {
+	switch value := value.(type) {
+	case *OrderedMap:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range value.Keys {
+			child, err := orderedNode(value.Values[key])
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		return node, nil
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i, item := range value {
+			child, err := orderedNode(item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return &node, nil
+}
+
+// resolveAlias returns the node an alias refers to, or node itself when it isn't an alias
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node
+}
@@ -0,0 +1,199 @@
+package order
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeOrdered(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		m, err := DecodeOrdered(strings.NewReader(`{
+  "name": "app",
+  "replicas": 3,
+  "ratio": 0.5,
+  "enabled": true,
+  "owner": null,
+  "version": "1",
+  "settings": {"timeout": 30, "debug": false},
+  "ports": [80, {"name": "https", "port": 443}]
+}`), "json")
+		if err != nil {
+			t.Fatalf("DecodeOrdered() returned an error: %v", err)
+		}
+
+		expectedKeys := []string{"name", "replicas", "ratio", "enabled", "owner", "version", "settings", "ports"}
+		if !reflect.DeepEqual(m.Keys, expectedKeys) {
+			t.Errorf("DecodeOrdered() returned incorrect keys: got %v, expected %v", m.Keys, expectedKeys)
+		}
+
+		if m.Values["name"] != "app" || m.Values["replicas"] != 3 || m.Values["ratio"] != 0.5 ||
+			m.Values["enabled"] != true || m.Values["owner"] != nil || m.Values["version"] != "1" {
+			t.Errorf("DecodeOrdered() returned incorrect scalar values: %v", m.Values)
+		}
+
+		settings, ok := m.Values["settings"].(*OrderedMap)
+		if !ok || !reflect.DeepEqual(settings.Keys, []string{"timeout", "debug"}) {
+			t.Errorf("DecodeOrdered() returned incorrect nested mapping: %#v", m.Values["settings"])
+		}
+
+		ports, ok := m.Values["ports"].([]any)
+		if !ok || len(ports) != 2 || ports[0] != 80 {
+			t.Fatalf("DecodeOrdered() returned incorrect sequence: %#v", m.Values["ports"])
+		}
+		if port, ok := ports[1].(*OrderedMap); !ok || !reflect.DeepEqual(port.Keys, []string{"name", "port"}) {
+			t.Errorf("DecodeOrdered() returned incorrect mapping inside a sequence: %#v", ports[1])
+		}
+	})
+
+	t.Run("JSON numbers", func(t *testing.T) {
+		m, err := DecodeOrdered(strings.NewReader(`{"t": 1700000000, "big": 12345678901234567, "small": 0.000001, "exp": 2e3}`), "json")
+		if err != nil {
+			t.Fatalf("DecodeOrdered() returned an error: %v", err)
+		}
+
+		expected := map[string]any{"t": 1700000000, "big": 12345678901234567, "small": 0.000001, "exp": 2000.0}
+		if !reflect.DeepEqual(m.Values, expected) {
+			t.Errorf("DecodeOrdered() returned incorrect numbers: got %#v, expected %#v", m.Values, expected)
+		}
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		m, err := DecodeOrdered(strings.NewReader(`defaults: &defaults
+  zone: a
+  size: small
+web: *defaults
+ref: !Ref Bucket
+`), ".yaml")
+		if err != nil {
+			t.Fatalf("DecodeOrdered() returned an error: %v", err)
+		}
+
+		if !reflect.DeepEqual(m.Keys, []string{"defaults", "web", "ref"}) {
+			t.Errorf("DecodeOrdered() returned incorrect keys: %v", m.Keys)
+		}
+		if web, ok := m.Values["web"].(*OrderedMap); !ok || !reflect.DeepEqual(web.Keys, []string{"zone", "size"}) {
+			t.Errorf("DecodeOrdered() did not resolve an alias: %#v", m.Values["web"])
+		}
+		if m.Values["ref"] != "Bucket" {
+			t.Errorf("DecodeOrdered() returned incorrect value for a custom tag: %#v", m.Values["ref"])
+		}
+	})
+
+	t.Run("Key without a value", func(t *testing.T) {
+		RegisterParser("dangling", danglingKeyParser{})
+
+		m, err := DecodeOrdered(strings.NewReader(""), "dangling")
+		if err != nil {
+			t.Fatalf("DecodeOrdered() returned an error: %v", err)
+		}
+		if !reflect.DeepEqual(m.Keys, []string{"first"}) {
+			t.Errorf("DecodeOrdered() returned incorrect keys for a mapping with a trailing key: %v", m.Keys)
+		}
+	})
+
+	t.Run("Not a mapping", func(t *testing.T) {
+		_, err := DecodeOrdered(strings.NewReader("- a\n- b\n"), "yaml")
+		if err == nil {
+			t.Errorf("DecodeOrdered() did not return an error for a sequence document")
+		}
+	})
+}
+
+func TestDecodeOrderedErrorPositions(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		content  string
+		expected string
+	}{
+		{"JSON syntax error", "json", "{\n  \"name\": \"app\",\n  \"port\": 80,,\n}", "invalid character ',' looking for beginning of value at line 3, column 14"},
+		{"JSON non-string key", "json", "{\n  \"a\": 1,\n  \"b\": {1: 2}\n}", "at line 3, column 9"},
+		{"JSON not an object", "json", "\n  [1, 2]", "expected document to be a mapping"},
+		{"JSON scalar", "json", "\n  42", "expected JSON object or array at line 2, column 3"},
+		{"JSON truncated", "json", "{\n  \"name\": \"app\"", "unexpected end of JSON input at line 2, column 16"},
+		{"YAML syntax error", "yaml", "name: app\nkey: value: nested\n", "line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeOrdered(strings.NewReader(tt.content), tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("DecodeOrdered() returned %v, expected an error containing %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateOrderedMap(t *testing.T) {
+	schema := []*SchemaProperty{
+		{Name: "name"},
+		{Name: "spec", Properties: []*SchemaProperty{{Name: "replicas"}, {Name: "image"}}},
+		{Name: "ports", Items: &SchemaProperty{Properties: []*SchemaProperty{{Name: "name"}, {Name: "port"}}}},
+	}
+
+	spec := func(keys ...string) *OrderedMap {
+		return &OrderedMap{Keys: keys, Values: map[string]any{"replicas": 2, "image": "nginx"}}
+	}
+	port := func(keys ...string) *OrderedMap {
+		return &OrderedMap{Keys: keys, Values: map[string]any{"name": "http", "port": 80}}
+	}
+	document := func(keys []string, values map[string]any) *OrderedMap {
+		return &OrderedMap{Keys: keys, Values: values}
+	}
+
+	tests := []struct {
+		name     string
+		m        *OrderedMap
+		expected string
+	}{
+		{
+			name: "Keys in order",
+			m: document([]string{"name", "spec", "ports"},
+				map[string]any{"name": "app", "spec": spec("replicas", "image"), "ports": []any{port("name", "port")}}),
+		},
+		{
+			name:     "Root keys out of order",
+			m:        document([]string{"spec", "name"}, map[string]any{"name": "app", "spec": spec("replicas", "image")}),
+			expected: "properties out of order: 'spec' should come after 'name'",
+		},
+		{
+			name:     "Nested keys out of order",
+			m:        document([]string{"name", "spec"}, map[string]any{"name": "app", "spec": spec("image", "replicas")}),
+			expected: "in property 'spec': properties out of order: 'image' should come after 'replicas'",
+		},
+		{
+			name: "Array item keys out of order",
+			m: document([]string{"ports"},
+				map[string]any{"ports": []any{port("name", "port"), port("port", "name")}}),
+			expected: "in property 'ports[1]': properties out of order: 'port' should come after 'name'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOrderedMap(tt.m, schema)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("ValidateOrderedMap() returned an error for keys in order: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("ValidateOrderedMap() returned %v, expected an error containing %q", err, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Decoded JSON", func(t *testing.T) {
+		m, err := DecodeOrdered(strings.NewReader(`{"spec": {"replicas": 1}, "name": "app"}`), "json")
+		if err != nil {
+			t.Fatalf("DecodeOrdered() returned an error: %v", err)
+		}
+
+		if err := ValidateOrderedMap(m, schema); err == nil {
+			t.Errorf("ValidateOrderedMap() returned no error for decoded keys out of order")
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package order
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expandDottedKeys returns a copy of the mapping node found at path in which dotted keys such as personal.name
+// are nested under a key named by their first segment, validated under LintOptions.DottedKeys. A group takes the
+// position of its first key, and keys of a group written apart from the rest of it are reported.
+// The nested keys keep the position of the dotted key they come from
+func (v *validator) expandDottedKeys(node *yaml.Node, path []string) *yaml.Node {
+	if !hasDottedKey(node) {
+		return node
+	}
+
+	expanded := &yaml.Node{Kind: yaml.MappingNode, Tag: node.Tag, Line: node.Line, Column: node.Column}
+	groups := make(map[string]*yaml.Node) // Nested mappings by the name of their group
+	last := make(map[string]string)       // Last key written in each group
+	previous := ""
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+		name, rest, dotted := strings.Cut(keyNode.Value, ".")
+		dotted = dotted && name != "" && rest != "" && keyNode.Kind == yaml.ScalarNode && !isMergeKey(keyNode)
+		if !dotted && valueNode.Kind != yaml.MappingNode {
+			expanded.Content = append(expanded.Content, keyNode, valueNode)
+			previous = keyNode.Value
+			continue
+		}
+		if !dotted {
+			name = keyNode.Value
+		}
+
+		group, ok := groups[name]
+		if !ok {
+			groupKey := keyNode
+			if dotted {
+				groupKey = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name, Line: keyNode.Line, Column: keyNode.Column}
+			}
+			group = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: keyNode.Line, Column: keyNode.Column}
+			groups[name] = group
+			expanded.Content = append(expanded.Content, groupKey, group)
+		} else if previous != name {
+			v.report(path, keyNode, "properties out of group: '"+keyNode.Value+"' should be written next to '"+last[name]+"'")
+		}
+
+		if dotted {
+			nestedKey := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: rest, Line: keyNode.Line, Column: keyNode.Column}
+			group.Content = append(group.Content, nestedKey, valueNode)
+		} else {
+			group.Content = append(group.Content, valueNode.Content...)
+		}
+		last[name] = keyNode.Value
+		previous = name
+	}
+
+	return expanded
+}
+
+// hasDottedKey reports whether a key of the mapping node contains a dot
+func hasDottedKey(node *yaml.Node) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.Contains(node.Content[i].Value, ".") {
+			return true
+		}
+	}
+
+	return false
+}
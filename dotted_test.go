@@ -0,0 +1,94 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintDottedKeys(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+  "properties": {
+    "personal": {"properties": {"name": {}, "email": {}}},
+    "server": {"properties": {"tls": {"properties": {"cert": {}, "key": {}}}, "port": {}}},
+    "age": {}
+  }
+}`)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+		lines    []int
+	}{
+		{
+			name:    "Keys in order",
+			content: "personal.name: x\npersonal.email: y\nserver.tls.cert: a\nserver.tls.key: b\nserver.port: 80\nage: 3\n",
+		},
+		{
+			name:    "Keys out of order within a group",
+			content: "personal.email: y\npersonal.name: x\nserver.port: 80\nserver.tls.key: b\nserver.tls.cert: a\n",
+			expected: []string{
+				"in property 'personal': properties out of order: 'email' should come after 'name' according to the schema",
+				"in property 'server': properties out of order: 'port' should come after 'tls' according to the schema",
+				"in property 'server': in property 'tls': properties out of order: 'key' should come after 'cert' according to the schema",
+			},
+			lines: []int{1, 3, 4},
+		},
+		{
+			name:     "Groups out of order",
+			content:  "age: 3\npersonal.name: x\n",
+			expected: []string{"properties out of order: 'age' should come after 'personal' according to the schema"},
+			lines:    []int{1},
+		},
+		{
+			name:     "Group split by another key",
+			content:  "personal.name: x\nage: 3\npersonal.email: y\n",
+			expected: []string{"properties out of group: 'personal.email' should be written next to 'personal.name'"},
+			lines:    []int{3},
+		},
+		{
+			name:     "Dotted keys extending a nested mapping",
+			content:  "server:\n  port: 80\nserver.tls.cert: a\n",
+			expected: []string{"in property 'server': properties out of order: 'port' should come after 'tls' according to the schema"},
+			lines:    []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documentPath := writeTestFile(t, tempDir, "config.yaml", tt.content)
+
+			violations, err := lintAll(documentPath, schemaPath, LintOptions{DottedKeys: true})
+			if err != nil {
+				t.Fatalf("LintAll() returned an error: %v", err)
+			}
+
+			var got []string
+			var lines []int
+			for _, violation := range violations {
+				got = append(got, violation.Error())
+				lines = append(lines, violation.Line)
+			}
+			if !reflect.DeepEqual(got, tt.expected) || !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("LintAll() returned %q at lines %v, expected %q at lines %v", got, lines, tt.expected, tt.lines)
+			}
+		})
+	}
+
+	t.Run("Exact", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "exact.yaml",
+			"personal.name: x\npersonal.email: y\nserver.tls.cert: a\nserver.tls.key: b\nserver.port: 80\nage: 3\n")
+		if err := LintWithOptions(documentPath, schemaPath, LintOptions{DottedKeys: true, Exact: true}); err != nil {
+			t.Errorf("LintWithOptions() returned an error for dotted keys matching the schema: %v", err)
+		}
+	})
+
+	t.Run("Not expanded by default", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "default.yaml", "personal.email: y\npersonal.name: x\n")
+		if err := Lint(documentPath, schemaPath); err != nil {
+			t.Errorf("Lint() expanded dotted keys without DottedKeys: %v", err)
+		}
+	})
+}
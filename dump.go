@@ -0,0 +1,193 @@
+package order
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadSchema parses the JSON schema at jsonSchemaPath into its top-level properties, in schema order
+func LoadSchema(jsonSchemaPath string) ([]*SchemaProperty, error) {
+	schema, err := loadSchema(jsonSchemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Properties, nil
+}
+
+// ValidateSchemaConsistency checks that properties form a well-structured tree, as LoadSchema produces it:
+// every property is non-nil and named, and no level holds two properties of the same name.
+// Levels include the items of arrays and the branches of discriminated unions
+func ValidateSchemaConsistency(properties []*SchemaProperty) error {
+	return validatePropertyTree(properties, "")
+}
+
+// validatePropertyTree checks the properties found at path, written like rules[].when, and their descendants
+func validatePropertyTree(properties []*SchemaProperty, path string) error {
+	at := path
+	if at == "" {
+		at = "the top level"
+	}
+
+	seen := make(map[string]bool, len(properties))
+	for i, prop := range properties {
+		switch {
+		case prop == nil:
+			return fmt.Errorf("nil property at index %d of %s", i, at)
+		case prop.Name == "":
+			return fmt.Errorf("property without a name at index %d of %s", i, at)
+		case seen[prop.Name]:
+			return fmt.Errorf("duplicate property %q at %s", prop.Name, at)
+		}
+		seen[prop.Name] = true
+
+		child := prop.Name
+		if path != "" {
+			child = path + "." + prop.Name
+		}
+		if err := validatePropertyTree(prop.Properties, child); err != nil {
+			return err
+		}
+		if prop.Items != nil {
+			if err := validatePropertyTree(prop.Items.Properties, child+"[]"); err != nil {
+				return err
+			}
+		}
+		for _, branch := range prop.OneOf {
+			if branch == nil {
+				return fmt.Errorf("nil oneOf branch at %s", child)
+			}
+			if err := validatePropertyTree(branch.Properties, child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SchemaKeysAtPath returns the names of the properties of the object path leads to in schema order, such as the keys
+// an editor should suggest next. path lists property names from the top level, a name followed by [] standing for
+// the items of an array property, like []string{"spec", "containers[]"}. An empty path returns the top-level keys
+func SchemaKeysAtPath(schema []*SchemaProperty, path []string) ([]string, error) {
+	properties := schema
+	for i, name := range path {
+		items := strings.HasSuffix(name, "[]")
+		name = strings.TrimSuffix(name, "[]")
+
+		var found *SchemaProperty
+		for _, prop := range properties {
+			if prop.Name == name {
+				found = prop
+				break
+			}
+		}
+
+		at := strings.Join(path[:i+1], ".")
+		switch {
+		case found == nil:
+			return nil, fmt.Errorf("no property %q in schema at %s", name, at)
+		case items && found.Items == nil:
+			return nil, fmt.Errorf("property %q at %s isn't an array with items", name, at)
+		case items:
+			found = found.Items
+		}
+		if len(found.Properties) == 0 {
+			return nil, fmt.Errorf("property %q at %s has no nested properties", name, at)
+		}
+
+		properties = found.Properties
+	}
+
+	keys := make([]string, 0, len(properties))
+	for _, prop := range properties {
+		keys = append(keys, prop.Name)
+	}
+
+	return keys, nil
+}
+
+// DumpSchema writes the property tree to w, one property per line in schema order and indented by nesting level,
+// followed by the annotations that affect validation. The properties of array items follow a [] line and those of
+// oneOf branches a line listing their discriminator values. It helps checking that a schema was parsed as intended
+func DumpSchema(w io.Writer, properties []*SchemaProperty) error {
+	return dumpProperties(w, properties, "")
+}
+
+// dumpProperties writes properties and their children to w, prefixing each line with indent
+func dumpProperties(w io.Writer, properties []*SchemaProperty, indent string) error {
+	for _, prop := range properties {
+		line := indent + prop.Name
+		if annotations := propertyAnnotations(prop); len(annotations) > 0 {
+			line += " (" + strings.Join(annotations, ", ") + ")"
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if err := dumpProperties(w, prop.Properties, indent+"  "); err != nil {
+			return err
+		}
+
+		// Branches of discriminated unions are listed under a line naming the values selecting them
+		if prop.Discriminator != "" {
+			for _, branch := range prop.OneOf {
+				if _, err := fmt.Fprintln(w, indent+"  oneOf "+strings.Join(branch.DiscriminatorValues, ", ")); err != nil {
+					return err
+				}
+				if err := dumpProperties(w, branch.Properties, indent+"    "); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Array items are listed under a [] line
+		if prop.Items != nil {
+			if _, err := fmt.Fprintln(w, indent+"  []"); err != nil {
+				return err
+			}
+			if err := dumpProperties(w, prop.Items.Properties, indent+"    "); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// propertyAnnotations describes the settings of prop besides its name and children
+func propertyAnnotations(prop *SchemaProperty) []string {
+	var annotations []string
+	if prop.Required {
+		annotations = append(annotations, "required")
+	}
+	if prop.Deprecated {
+		annotations = append(annotations, "deprecated")
+	}
+	if prop.Const != nil {
+		annotations = append(annotations, "const: "+strconv.Quote(*prop.Const))
+	}
+	if prop.Discriminator != "" {
+		annotations = append(annotations, "discriminator: "+prop.Discriminator)
+	}
+	if prop.Section != "" {
+		annotations = append(annotations, "x-section: "+prop.Section)
+	}
+	if prop.Order != "" {
+		annotations = append(annotations, "x-order: "+prop.Order)
+	}
+	if prop.SortItems {
+		annotations = append(annotations, "x-sort-items")
+	}
+	if len(prop.OrderConstraints) > 0 {
+		var constraints []string
+		for _, constraint := range prop.OrderConstraints {
+			constraints = append(constraints, constraint[0]+" < "+constraint[1])
+		}
+		annotations = append(annotations, "x-order-constraints: "+strings.Join(constraints, ", "))
+	}
+
+	return annotations
+}
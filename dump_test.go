@@ -0,0 +1,107 @@
+package order
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDumpSchema(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+  "required": ["name"],
+  "properties": {
+    "name": {},
+    "kind": {"const": "Service", "deprecated": true},
+    "spec": {
+      "properties": {
+        "host": {"x-section": "server"},
+        "port": {"x-section": "server"}
+      },
+      "x-order-constraints": [["host", "port"]]
+    },
+    "labels": {"x-order": "alphabetical"},
+    "rules": {"items": {"properties": {"when": {}, "then": {}}}}
+  }
+}`)
+
+	properties, err := LoadSchema(schemaPath)
+	if err != nil {
+		t.Fatalf("LoadSchema() returned an error: %v", err)
+	}
+
+	var b strings.Builder
+	if err := DumpSchema(&b, properties); err != nil {
+		t.Fatalf("DumpSchema() returned an error: %v", err)
+	}
+
+	expected := `name (required)
+kind (deprecated, const: "Service")
+spec (x-order-constraints: host < port)
+  host (x-section: server)
+  port (x-section: server)
+labels (x-order: alphabetical)
+rules
+  []
+    when
+    then
+`
+	if b.String() != expected {
+		t.Errorf("DumpSchema() wrote:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+}
+
+func TestSchemaKeysAtPath(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+  "properties": {
+    "name": {},
+    "spec": {
+      "properties": {
+        "replicas": {},
+        "containers": {"items": {"properties": {"name": {}, "image": {}, "ports": {}}}}
+      }
+    }
+  }
+}`)
+
+	properties, err := LoadSchema(schemaPath)
+	if err != nil {
+		t.Fatalf("LoadSchema() returned an error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     []string
+		expected []string
+		err      string
+	}{
+		{name: "Top level", expected: []string{"name", "spec"}},
+		{name: "Nested object", path: []string{"spec"}, expected: []string{"replicas", "containers"}},
+		{name: "Array items", path: []string{"spec", "containers[]"}, expected: []string{"name", "image", "ports"}},
+		{name: "Missing property", path: []string{"spec", "volumes"}, err: `no property "volumes" in schema at spec.volumes`},
+		{name: "Not an object", path: []string{"name"}, err: `property "name" at name has no nested properties`},
+		{name: "Not an array", path: []string{"spec[]"}, err: `property "spec" at spec[] isn't an array with items`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := SchemaKeysAtPath(properties, tt.path)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("SchemaKeysAtPath() returned %v, expected error %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SchemaKeysAtPath() returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("SchemaKeysAtPath() returned %v, expected %v", keys, tt.expected)
+			}
+		})
+	}
+}
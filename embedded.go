@@ -0,0 +1,128 @@
+package order
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EmbeddedDocument names a string value holding a whole document, such as a config file stored in a Kubernetes
+// ConfigMap, and the schema its own keys follow
+type EmbeddedDocument struct {
+	// Path lists the keys leading from the root of the document to the string value, such as
+	// []string{"data", "config.yaml"}
+	Path []string
+	// Format is the extension of the parser reading the value, such as ".yaml" or ".json"
+	Format string
+	// Properties is the schema of the embedded document, such as returned by LoadSchema
+	Properties []*SchemaProperty
+}
+
+// checkEmbeddedFormats checks that a parser is registered for the format of every embedded document
+func checkEmbeddedFormats(embedded []EmbeddedDocument) error {
+	for _, document := range embedded {
+		if _, err := parserFor(document.Format); err != nil {
+			return fmt.Errorf("embedded document at %s: %w", strings.Join(document.Path, "."), err)
+		}
+	}
+
+	return nil
+}
+
+// checkEmbeddedDocuments validates the string values of the root mapping node named by LintOptions.Embedded
+// against their schemas. Paths leading nowhere or to a value that isn't a string are skipped
+func (v *validator) checkEmbeddedDocuments(node *yaml.Node) {
+	for _, document := range v.opts.Embedded {
+		keyNode, valueNode := lookupKeyPath(node, document.Path)
+		if valueNode == nil || valueNode.Kind != yaml.ScalarNode || valueNode.ShortTag() != "!!str" {
+			continue
+		}
+
+		if !v.checkEmbeddedDocument(keyNode, valueNode, document) {
+			return
+		}
+	}
+}
+
+// lookupKeyPath returns the key and value nodes found by following path from the mapping node, or nils when
+// a key is missing
+func lookupKeyPath(node *yaml.Node, path []string) (*yaml.Node, *yaml.Node) {
+	var keyNode *yaml.Node
+	for _, key := range path {
+		node = resolveAlias(node)
+		if node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				keyNode, node = node.Content[i], node.Content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+	}
+
+	return keyNode, resolveAlias(node)
+}
+
+// checkEmbeddedDocument parses the string held by valueNode and validates it against the schema of document,
+// returning whether validation should continue. Violations are located in the enclosing document, at their own
+// line for literal block scalars and at the key of the value otherwise
+func (v *validator) checkEmbeddedDocument(keyNode, valueNode *yaml.Node, document EmbeddedDocument) bool {
+	parentPath := document.Path[:len(document.Path)-1]
+
+	content := []byte(valueNode.Value)
+	root, err := parseContent(content, document.Format)
+	if err != nil {
+		return v.report(parentPath, keyNode, "embedded document couldn't be parsed: "+err.Error())
+	}
+
+	// Checks of the enclosing document, and its source snippets, don't apply inside the value
+	opts := v.opts
+	opts.Embedded = nil
+	opts.ShowSource = false
+	opts.RequireFirstKey = ""
+	opts.RequireOptInKey = ""
+	opts.RequireTrailingNewline = false
+	opts.checked = nil
+	opts.skipped = nil
+
+	indent := v.blockIndent(valueNode)
+	lintDocument(content, root, &SchemaProperty{Properties: document.Properties}, opts, func(violation *Violation) bool {
+		violation.Path = append(append([]string(nil), document.Path...), violation.Path...)
+		if indent >= 0 {
+			violation.Line += valueNode.Line
+			violation.Column += indent
+		} else {
+			violation.Line, violation.Column = keyNode.Line, keyNode.Column
+		}
+		return v.reportViolation(violation)
+	})
+
+	return !v.stopped
+}
+
+// blockIndent returns the indentation of the content of a literal block scalar starting on the line after its
+// indicator, such as data: |, or -1 when the lines of the value can't be located in the source
+func (v *validator) blockIndent(node *yaml.Node) int {
+	if node.Style&yaml.LiteralStyle == 0 || len(v.content) == 0 {
+		return -1
+	}
+
+	lines := bytes.Split(v.content, []byte("\n"))
+	for n := node.Line; n < len(lines); n++ {
+		line := bytes.TrimRight(lines[n], "\r")
+		if trimmed := bytes.TrimLeft(line, " "); len(trimmed) > 0 {
+			return len(line) - len(trimmed)
+		}
+	}
+
+	return -1
+}
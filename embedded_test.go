@@ -0,0 +1,63 @@
+package order
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLintEmbeddedDocuments(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"kind": {}, "metadata": {}, "data": {}}}`)
+	configSchemaPath := writeTestFile(t, tempDir, "config.json", `{"properties": {"name": {}, "server": {"properties": {"host": {}, "port": {}}}}}`)
+	configMapPath := writeTestFile(t, tempDir, "configmap.yaml", `kind: ConfigMap
+metadata: {}
+data:
+  config.yaml: |
+    name: web
+    server:
+      port: 80
+      host: localhost
+  config.json: '{"server": {"host": "localhost"}, "name": "web"}'
+  broken.yaml: "name: [web"
+`)
+
+	properties, err := LoadSchema(configSchemaPath)
+	if err != nil {
+		t.Fatalf("LoadSchema() returned an error: %v", err)
+	}
+	opts := LintOptions{Embedded: []EmbeddedDocument{
+		{Path: []string{"data", "config.yaml"}, Format: ".yaml", Properties: properties},
+		{Path: []string{"data", "config.json"}, Format: ".json", Properties: properties},
+		{Path: []string{"data", "broken.yaml"}, Format: ".yaml", Properties: properties},
+		{Path: []string{"data", "missing.yaml"}, Format: ".yaml", Properties: properties},
+	}}
+
+	violations, err := lintAll(configMapPath, schemaPath, opts)
+	if err != nil {
+		t.Fatalf("LintAll() returned an error: %v", err)
+	}
+
+	var got []string
+	for _, violation := range violations {
+		got = append(got, fmt.Sprintf("%d:%d: %s", violation.Line, violation.Column, violation.Error()))
+	}
+	expected := []string{
+		"7:7: in property 'data': in property 'config.yaml': in property 'server': " +
+			"properties out of order: 'port' should come after 'host' according to the schema",
+		"9:3: in property 'data': in property 'config.json': " +
+			"properties out of order: 'server' should come after 'name' according to the schema",
+		"10:3: in property 'data': embedded document couldn't be parsed: yaml: line 1: did not find expected ',' or ']'",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("LintAll() returned %q, expected %q", got, expected)
+	}
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		opts := LintOptions{Embedded: []EmbeddedDocument{{Path: []string{"data", "config.toml"}, Format: ".toml"}}}
+		if err := LintWithOptions(configMapPath, schemaPath, opts); err == nil {
+			t.Errorf("LintWithOptions() returned no error for an embedded format without a parser")
+		}
+	})
+}
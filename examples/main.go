@@ -3,18 +3,20 @@ package main
 import (
 	"fmt"
 	"log"
-	"order"
+
+	"github.com/roscrl/order"
 )
 
 func main() {
+	// Errors name the file they concern
 	err := order.Lint("config.yaml", "schema.json")
 	if err != nil {
-		log.Fatalf("error linting config.yaml file properties order against json schema: %v", err)
+		log.Fatalf("error linting properties order against json schema: %v", err)
 	}
 
 	err = order.Lint("config.json", "schema.json")
 	if err != nil {
-		log.Fatalf("error linting config.json file properties order against json schema: %v", err)
+		log.Fatalf("error linting properties order against json schema: %v", err)
 	}
 
 	fmt.Println("Properties order is valid")
@@ -0,0 +1,62 @@
+package order
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StructureFingerprint returns a hex-encoded SHA-256 hash of the key structure of the YAML or JSON file at path:
+// its key names, their order and nesting, and the items of its sequences. Values are ignored, so files differing
+// only in their scalar values share a fingerprint while moving, renaming or nesting a key changes it
+func StructureFingerprint(path string) (string, error) {
+	_, root, err := parseDocument(path, LintOptions{})
+	if err != nil {
+		return "", withPath(path, err)
+	}
+
+	h := sha256.New()
+	for _, node := range root.Content {
+		writeStructure(h, node, make(map[*yaml.Node]bool))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeStructure writes a serialization of the keys and nesting of node to h, scalars all being written alike.
+// ancestors holds the mappings and sequences containing node, guarding against aliases referring to them
+func writeStructure(h hash.Hash, node *yaml.Node, ancestors map[*yaml.Node]bool) {
+	node = resolveAlias(node)
+	if ancestors[node] {
+		io.WriteString(h, "*")
+		return
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		ancestors[node] = true
+		io.WriteString(h, "{")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			io.WriteString(h, strconv.Quote(node.Content[i].Value)+":")
+			writeStructure(h, node.Content[i+1], ancestors)
+			io.WriteString(h, ",")
+		}
+		io.WriteString(h, "}")
+		delete(ancestors, node)
+	case yaml.SequenceNode:
+		ancestors[node] = true
+		io.WriteString(h, "[")
+		for _, item := range node.Content {
+			writeStructure(h, item, ancestors)
+			io.WriteString(h, ",")
+		}
+		io.WriteString(h, "]")
+		delete(ancestors, node)
+	default:
+		io.WriteString(h, "_")
+	}
+}
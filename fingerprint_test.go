@@ -0,0 +1,48 @@
+package order
+
+import (
+	"testing"
+)
+
+func TestStructureFingerprint(t *testing.T) {
+	tempDir := t.TempDir()
+
+	fingerprint := func(name, content string) string {
+		t.Helper()
+
+		got, err := StructureFingerprint(writeTestFile(t, tempDir, name, content))
+		if err != nil {
+			t.Fatalf("StructureFingerprint() returned an error for %s: %v", name, err)
+		}
+		return got
+	}
+
+	base := fingerprint("base.yaml", "name: web\nspec:\n  replicas: 2\n  ports: [80, 443]\n")
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		same    bool
+	}{
+		{"Different values", "values.yaml", "name: api\nspec:\n  replicas: 5\n  ports: [8080, 8443]\n", true},
+		{"Same structure in JSON", "base.json", `{"name": "x", "spec": {"replicas": 1, "ports": [1, 2]}}`, true},
+		{"Keys reordered", "reordered.yaml", "spec:\n  replicas: 2\n  ports: [80, 443]\nname: web\n", false},
+		{"Key renamed", "renamed.yaml", "title: web\nspec:\n  replicas: 2\n  ports: [80, 443]\n", false},
+		{"Key nested differently", "nested.yaml", "name: web\nspec:\n  replicas: 2\nports: [80, 443]\n", false},
+		{"Another item", "items.yaml", "name: web\nspec:\n  replicas: 2\n  ports: [80, 443, 8080]\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(tt.file, tt.content); (got == base) != tt.same {
+				t.Errorf("StructureFingerprint() returned %s for %s and %s for the base file, expected them to be equal: %v",
+					got, tt.file, base, tt.same)
+			}
+		})
+	}
+
+	if len(base) != 64 {
+		t.Errorf("StructureFingerprint() returned %q, expected a hex-encoded SHA-256 hash", base)
+	}
+}
@@ -0,0 +1,509 @@
+package order
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FixOptions configures Fix, FixBytes and FixReport
+type FixOptions struct {
+	// Annotate adds a comment above every moved key telling which schema position it was moved to.
+	// Fixing again replaces the comment instead of stacking another one. Only YAML documents can hold comments
+	Annotate bool
+
+	// Sort tunes how keys of objects marked "x-order": "alphabetical" are compared, as in LintOptions
+	Sort SortOptions
+
+	// Write makes FixReport save the reordered document, Fix always does
+	Write bool
+}
+
+// Move records a key moved by a fix within the mapping at Path, From and To being its 0-based indexes
+// among the keys of the mapping before and after the fix
+type Move struct {
+	Path     []string
+	Key      string
+	From, To int
+}
+
+// annotationPrefix starts the comments added by FixOptions.Annotate
+const annotationPrefix = "# order: "
+
+// Fix rewrites the YAML or JSON file at path so its properties follow the order of the JSON schema.
+// Keys missing from the schema keep their position, objects ordered with x-order-constraints are left untouched
+// and the file isn't written when nothing moved. Every document of a multi-document YAML file is reordered
+func Fix(path, jsonSchemaPath string, opts FixOptions) error {
+	opts.Write = true
+	_, err := FixReport(path, jsonSchemaPath, opts)
+
+	return err
+}
+
+// FixReport reorders the file at path like Fix, only saving the result when FixOptions.Write is set.
+// The report lists every key that moved, or would move, in Moves
+func FixReport(path, jsonSchemaPath string, opts FixOptions) (Report, error) {
+	schema, err := loadSchema(jsonSchemaPath)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return fixFile(path, jsonSchemaPath, schema, opts)
+}
+
+// fixFile reorders the file at path to follow schema, read from jsonSchemaPath, saving the result under
+// FixOptions.Write. Files are replaced atomically, so an interrupted fix never leaves a partial file
+func fixFile(path, jsonSchemaPath string, schema *SchemaProperty, opts FixOptions) (Report, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Report{}, err
+	}
+
+	fixed, moves, err := fixContent(content, filepath.Ext(path), schema, opts)
+	if err != nil {
+		return Report{}, withPath(path, err)
+	}
+
+	report := Report{File: path, Schema: jsonSchemaPath, SchemaSource: jsonSchemaPath, Moves: moves}
+	if !opts.Write || bytes.Equal(fixed, content) {
+		return report, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return report, writeFileAtomic(path, fixed, info.Mode().Perm())
+}
+
+// writeFileAtomic writes content to a temporary file next to path, then renames it over path,
+// so readers see either the previous content or the new one
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// FixBytes is like Fix but reorders content, parsed with the parser registered for ext, returning the result
+func FixBytes(content []byte, ext, jsonSchemaPath string, opts FixOptions) ([]byte, error) {
+	schema, err := loadSchema(jsonSchemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fixed, _, err := fixContent(content, ext, schema, opts)
+	return fixed, err
+}
+
+// fixContent reorders content, parsed with the parser registered for ext, to follow schema.
+// Every document of a multi-document YAML file is reordered.
+// It returns the reordered content along with the keys moved, content itself being returned when none moved
+func fixContent(content []byte, ext string, schema *SchemaProperty, opts FixOptions) ([]byte, []Move, error) {
+	documents, err := parseDocuments(content, ext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	isJSON := strings.TrimPrefix(ext, ".") == "json"
+	f := &fixer{annotate: opts.Annotate && !isJSON, sort: opts.Sort}
+	for _, root := range documents {
+		if len(root.Content) == 0 {
+			continue
+		}
+
+		// The comment heading the document stays at the top instead of following the key it was parsed onto
+		node := root.Content[0]
+		header := takeDocumentHeader(node, content)
+		f.reorderNode(node, schema, nil)
+		if header != "" {
+			node.Content[0].HeadComment = strings.TrimSuffix(header+"\n"+node.Content[0].HeadComment, "\n")
+		}
+
+		// Documents made of an array of objects follow the items schema, as when linting
+		if node.Kind == yaml.SequenceNode && schema.Items != nil && schema.Items.hasNestedOrder() {
+			for index, item := range node.Content {
+				f.reorderNode(item, schema.Items, indexPath(nil, index))
+			}
+		}
+	}
+	if len(f.moves) == 0 {
+		return content, nil, nil
+	}
+
+	var fixed []byte
+	if isJSON {
+		fixed, err = encodeJSONDocument(documents[0], content)
+	} else {
+		fixed, err = encodeYAMLDocuments(documents, content)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fixed, f.moves, nil
+}
+
+// parseDocuments parses every document of content with the parser registered for ext.
+// Only the YAML parser reads several documents, other parsers returning the one they parse
+func parseDocuments(content []byte, ext string) ([]*yaml.Node, error) {
+	parser, err := parserFor(ext)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := parser.(yamlParser); !ok {
+		root, err := parseContent(content, ext)
+		if err != nil {
+			return nil, err
+		}
+		return []*yaml.Node{root}, nil
+	}
+
+	var documents []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); errors.Is(err, io.EOF) {
+			return documents, nil
+		} else if err != nil {
+			return nil, err
+		}
+		documents = append(documents, &document)
+	}
+}
+
+// fixer reorders document mappings to follow a schema, recording the keys it moves
+type fixer struct {
+	annotate bool
+	sort     SortOptions
+	moves    []Move
+}
+
+// reorderNode sorts the keys of a mapping node, found at path, into schema order, recursing into nested mappings
+func (f *fixer) reorderNode(node *yaml.Node, schema *SchemaProperty, path []string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	if schema.Discriminator != "" {
+		if branch, _ := selectBranch(node, schema); branch != nil {
+			schema = branch
+		}
+	}
+
+	// Remember the original index of each key-value pair
+	type pair struct {
+		key, value *yaml.Node
+		index      int
+	}
+	var pairs []pair
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{key: node.Content[i], value: node.Content[i+1], index: i / 2})
+	}
+
+	reordered := append([]pair(nil), pairs...)
+	positions := make(map[string]int)
+	for i, prop := range schema.Properties {
+		positions[prop.Name] = i
+	}
+
+	switch {
+	case schema.Order == OrderAlphabetical:
+		sort.SliceStable(reordered, func(i, j int) bool {
+			return f.sort.less(reordered[i].key.Value, reordered[j].key.Value)
+		})
+	case len(schema.OrderConstraints) == 0:
+		// Only keys known to the schema move, sharing the slots they occupied between them
+		var slots []int
+		var known []pair
+		for i, p := range pairs {
+			if _, ok := positions[p.key.Value]; ok {
+				slots = append(slots, i)
+				known = append(known, p)
+			}
+		}
+
+		sort.SliceStable(known, func(i, j int) bool {
+			return positions[known[i].key.Value] < positions[known[j].key.Value]
+		})
+		for i, slot := range slots {
+			reordered[slot] = known[i]
+		}
+	}
+
+	node.Content = node.Content[:0]
+	for i, p := range reordered {
+		if p.index != i {
+			f.moves = append(f.moves, Move{Path: path, Key: p.key.Value, From: p.index, To: i})
+			if f.annotate {
+				annotation := "sorted alphabetically"
+				if schema.Order != OrderAlphabetical {
+					annotation = "reordered to match schema position " + strconv.Itoa(positions[p.key.Value]+1)
+				}
+				annotateKey(p.key, annotation)
+			}
+		}
+		node.Content = append(node.Content, p.key, p.value)
+	}
+
+	// Now recursively reorder nested properties
+	propertiesByName := indexPropertiesByName(schema.Properties)
+	for _, p := range reordered {
+		prop, ok := propertiesByName[p.key.Value]
+		if !ok {
+			continue
+		}
+
+		if p.value.Kind == yaml.SequenceNode && prop.Items != nil && prop.Items.hasNestedOrder() {
+			for index, item := range p.value.Content {
+				f.reorderNode(item, prop.Items, indexPath(appendPath(path, p.key.Value), index))
+			}
+			continue
+		}
+		if prop.hasNestedOrder() {
+			f.reorderNode(p.value, prop, appendPath(path, p.key.Value))
+		}
+	}
+}
+
+// takeDocumentHeader removes the comment heading a document from the first key of its root mapping node, where
+// yaml.v3 parses it when no blank line follows it, and returns it. Annotations left by a previous fix stay on the key.
+// A comment is only taken as the header when nothing but comments, blank lines and the --- marker precede it
+func takeDocumentHeader(node *yaml.Node, content []byte) string {
+	if node.Kind != yaml.MappingNode || len(node.Content) == 0 || node.Content[0].HeadComment == "" {
+		return ""
+	}
+	keyNode := node.Content[0]
+
+	lines := strings.Split(string(content), "\n")
+	for i := keyNode.Line - 2; i >= 0 && i < len(lines); i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "---" {
+			break
+		}
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return ""
+		}
+	}
+
+	var header, annotations []string
+	for _, line := range strings.Split(keyNode.HeadComment, "\n") {
+		if strings.HasPrefix(line, annotationPrefix) {
+			annotations = append(annotations, line)
+		} else {
+			header = append(header, line)
+		}
+	}
+	keyNode.HeadComment = strings.Join(annotations, "\n")
+
+	return strings.Join(header, "\n")
+}
+
+// annotateKey sets the annotation comment of a moved key, replacing the one left by a previous fix
+func annotateKey(keyNode *yaml.Node, annotation string) {
+	var lines []string
+	for _, line := range strings.Split(keyNode.HeadComment, "\n") {
+		if line != "" && !strings.HasPrefix(line, annotationPrefix) {
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, annotationPrefix+annotation)
+
+	keyNode.HeadComment = strings.Join(lines, "\n")
+}
+
+// encodeYAMLDocuments serialises YAML documents separated by ---, keeping the indentation of the first non-empty
+// document and the document start marker of original
+func encodeYAMLDocuments(documents []*yaml.Node, original []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if bytes.HasPrefix(original, []byte("---")) {
+		buf.WriteString("---\n")
+	}
+
+	indent := 2
+	for _, document := range documents {
+		if len(document.Content) > 0 {
+			indent = detectIndent(document.Content[0], indent)
+			break
+		}
+	}
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// detectIndent returns the indentation of the first nested mapping below node, or fallback when there is none
+func detectIndent(node *yaml.Node, fallback int) int {
+	if node.Kind != yaml.MappingNode {
+		return fallback
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if valueNode.Kind == yaml.MappingNode && len(valueNode.Content) > 0 && valueNode.Content[0].Line > keyNode.Line {
+			if indent := valueNode.Content[0].Column - keyNode.Column; indent > 0 {
+				return indent
+			}
+		}
+	}
+
+	return fallback
+}
+
+// encodeJSONDocument serialises a JSON document in the layout of original, keeping its final newline
+func encodeJSONDocument(root *yaml.Node, original []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, root.Content[0], detectJSONLayout(root.Content[0], original), ""); err != nil {
+		return nil, err
+	}
+	if bytes.HasSuffix(original, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// jsonLayout is the formatting of a JSON document, followed when writing it back
+type jsonLayout struct {
+	// indent is added at every nesting level, empty writing the document on a single line
+	indent string
+	// space follows the colons and commas of single line documents
+	space string
+}
+
+// detectJSONLayout returns the layout of the JSON value node parsed from original. Values whose first element
+// starts a new line are indented like that line, others are written on a single line, spaced unless minified
+func detectJSONLayout(node *yaml.Node, original []byte) jsonLayout {
+	if (node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode) && len(node.Content) > 0 &&
+		node.Content[0].Line > node.Line {
+		lines := bytes.Split(original, []byte("\n"))
+		outer, inner := leadingSpace(lines[node.Line-1]), leadingSpace(lines[node.Content[0].Line-1])
+		if indent, ok := strings.CutPrefix(inner, outer); ok && indent != "" {
+			return jsonLayout{indent: indent}
+		}
+		return jsonLayout{indent: "  "}
+	}
+
+	var compact bytes.Buffer
+	if json.Compact(&compact, original) == nil && compact.Len() == len(bytes.TrimSpace(original)) {
+		return jsonLayout{}
+	}
+
+	return jsonLayout{space: " "}
+}
+
+// leadingSpace returns the spaces and tabs line starts with
+func leadingSpace(line []byte) string {
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// writeJSONNode writes node, nested indent deep, as JSON in the given layout
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node, layout jsonLayout, indent string) error {
+	node = resolveAlias(node)
+
+	// Elements start a new line unless the layout is a single line
+	separator := "," + layout.space
+	colon := ":" + layout.space
+	lineBreak := func(indent string) {}
+	if layout.indent != "" {
+		separator, colon = ",", ": "
+		lineBreak = func(indent string) {
+			buf.WriteString("\n" + indent)
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+
+		buf.WriteString("{")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			lineBreak(indent + layout.indent)
+			writeJSONString(buf, node.Content[i].Value)
+			buf.WriteString(colon)
+			if err := writeJSONNode(buf, node.Content[i+1], layout, indent+layout.indent); err != nil {
+				return err
+			}
+			if i+3 < len(node.Content) {
+				buf.WriteString(separator)
+			}
+		}
+		lineBreak(indent)
+		buf.WriteString("}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+
+		buf.WriteString("[")
+		for i, item := range node.Content {
+			lineBreak(indent + layout.indent)
+			if err := writeJSONNode(buf, item, layout, indent+layout.indent); err != nil {
+				return err
+			}
+			if i+1 < len(node.Content) {
+				buf.WriteString(separator)
+			}
+		}
+		lineBreak(indent)
+		buf.WriteString("]")
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!int", "!!float", "!!bool", "!!null":
+			buf.WriteString(node.Value)
+		default:
+			writeJSONString(buf, node.Value)
+		}
+	default:
+		return errors.New("unexpected node in JSON document")
+	}
+
+	return nil
+}
+
+// writeJSONString writes s as a JSON string, leaving <, > and & unescaped as documents usually write them
+func writeJSONString(buf *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+
+	// Encode terminates the value with a newline
+	buf.Truncate(buf.Len() - 1)
+}
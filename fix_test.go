@@ -0,0 +1,350 @@
+package order
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFix(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+		"properties": {
+			"name": {},
+			"spec": {"properties": {"replicas": {}, "image": {}}}
+		}
+	}`)
+
+	t.Run("Reorders YAML keys recursively", func(t *testing.T) {
+		path := writeTestFile(t, tempDir, "doc.yaml", "spec:\n  image: nginx\n  extra: true\n  replicas: 2\nname: web\n")
+
+		err := Fix(path, schemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("Fix() returned an error: %v", err)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read fixed file: %v", err)
+		}
+
+		expected := "name: web\nspec:\n  replicas: 2\n  extra: true\n  image: nginx\n"
+		if string(content) != expected {
+			t.Errorf("Fix() wrote %q, expected %q", content, expected)
+		}
+
+		if err := Lint(path, schemaPath); err != nil {
+			t.Errorf("Lint() returned an error for a fixed file: %v", err)
+		}
+	})
+
+	t.Run("Reorders JSON keys", func(t *testing.T) {
+		content := []byte(`{"spec": {"image": "nginx", "replicas": 2}, "name": "web", "tags": [1, null]}` + "\n")
+
+		fixed, err := FixBytes(content, ".json", schemaPath, FixOptions{Annotate: true})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+
+		expected := `{"name": "web", "spec": {"replicas": 2, "image": "nginx"}, "tags": [1, null]}` + "\n"
+		if string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+	})
+
+	t.Run("Reorders every YAML document", func(t *testing.T) {
+		content := []byte("---\nspec:\n  image: a\n  replicas: 1\nname: first\n---\nspec: {}\nname: second\n")
+
+		fixed, err := FixBytes(content, ".yaml", schemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+
+		expected := "---\nname: first\nspec:\n  replicas: 1\n  image: a\n---\nname: second\nspec: {}\n"
+		if string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+	})
+
+	t.Run("Keeps JSON numbers as written", func(t *testing.T) {
+		content := []byte(`{"spec": {"image": 1700000000, "replicas": 12345678901234567}, "name": 0.000001, "tags": [1.50, 2E3, -0]}`)
+
+		fixed, err := FixBytes(content, ".json", schemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+
+		expected := `{"name": 0.000001, "spec": {"replicas": 12345678901234567, "image": 1700000000}, "tags": [1.50, 2E3, -0]}`
+		if string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+	})
+
+	t.Run("Key without a value", func(t *testing.T) {
+		RegisterParser("dangling", danglingKeyParser{})
+		reversedSchemaPath := writeTestFile(t, tempDir, "reversed.json", `{"properties": {"second": {}, "first": {}}}`)
+
+		_, err := FixBytes(nil, ".dangling", reversedSchemaPath, FixOptions{})
+		if err != nil {
+			t.Errorf("FixBytes() returned an error for a mapping with a trailing key: %v", err)
+		}
+
+		root, err := danglingKeyParser{}.Parse(nil)
+		if err != nil {
+			t.Fatalf("Parse() returned an error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := writeJSONNode(&buf, root, jsonLayout{indent: "  "}, ""); err != nil {
+			t.Fatalf("writeJSONNode() returned an error: %v", err)
+		}
+		if expected := "{\n  \"first\": {}\n}"; buf.String() != expected {
+			t.Errorf("writeJSONNode() wrote %q, expected %q", buf.String(), expected)
+		}
+	})
+
+	t.Run("Keeps the JSON layout", func(t *testing.T) {
+		tests := []struct {
+			name, content, expected string
+		}{
+			{
+				name:     "Four spaces",
+				content:  "{\n    \"spec\": {\n        \"image\": \"nginx\",\n        \"replicas\": 2\n    },\n    \"name\": \"web\"\n}\n",
+				expected: "{\n    \"name\": \"web\",\n    \"spec\": {\n        \"replicas\": 2,\n        \"image\": \"nginx\"\n    }\n}\n",
+			},
+			{
+				name:     "Tabs",
+				content:  "{\n\t\"spec\": [],\n\t\"name\": \"web\"\n}",
+				expected: "{\n\t\"name\": \"web\",\n\t\"spec\": []\n}",
+			},
+			{
+				name:     "Minified",
+				content:  `{"spec":{"image":"nginx","replicas":2},"name":"web"}`,
+				expected: `{"name":"web","spec":{"replicas":2,"image":"nginx"}}`,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				fixed, err := FixBytes([]byte(tt.content), ".json", schemaPath, FixOptions{})
+				if err != nil {
+					t.Fatalf("FixBytes() returned an error: %v", err)
+				}
+				if string(fixed) != tt.expected {
+					t.Errorf("FixBytes() returned %q, expected %q", fixed, tt.expected)
+				}
+			})
+		}
+	})
+
+	t.Run("Keeps HTML characters of JSON strings", func(t *testing.T) {
+		content := []byte(`{"spec": {}, "name": "<x&y>", "<a&b>": "a > b"}`)
+
+		fixed, err := FixBytes(content, ".json", schemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+
+		expected := `{"name": "<x&y>", "spec": {}, "<a&b>": "a > b"}`
+		if string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+	})
+
+	t.Run("Reorders sequence elements", func(t *testing.T) {
+		itemsSchemaPath := writeTestFile(t, tempDir, "items.json", `{"properties": {"rules": {"items": {"properties": {"when": {}, "then": {}}}}}}`)
+
+		fixed, err := FixBytes([]byte("rules:\n  - then: b\n    when: a\n"), ".yaml", itemsSchemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+
+		expected := "rules:\n  - when: a\n    then: b\n"
+		if string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+	})
+
+	t.Run("Reorders the items of a top-level array", func(t *testing.T) {
+		arraySchemaPath := writeTestFile(t, tempDir, "array.json", `{"items": {"properties": {"a": {}, "b": {}}}}`)
+		arrayPath := writeTestFile(t, tempDir, "list.json", `[{"b": 1, "a": 1}, {"a": 2}]`)
+
+		report, err := FixReport(arrayPath, arraySchemaPath, FixOptions{Write: true})
+		if err != nil {
+			t.Fatalf("FixReport() returned an error: %v", err)
+		}
+		if len(report.Moves) != 2 || !reflect.DeepEqual(report.Moves[0].Path, []string{"[0]"}) {
+			t.Errorf("FixReport() returned unexpected moves: %+v", report.Moves)
+		}
+
+		content, err := os.ReadFile(arrayPath)
+		if err != nil {
+			t.Fatalf("Failed to read fixed file: %v", err)
+		}
+		if expected := `[{"a": 1, "b": 1}, {"a": 2}]`; string(content) != expected {
+			t.Errorf("FixReport() wrote %q, expected %q", content, expected)
+		}
+		if err := Lint(arrayPath, arraySchemaPath); err != nil {
+			t.Errorf("Lint() returned an error for a fixed file: %v", err)
+		}
+	})
+
+	t.Run("Follows the discriminated branch", func(t *testing.T) {
+		unionSchemaPath := writeTestFile(t, tempDir, "union.json", `{
+			"discriminator": {"propertyName": "kind"},
+			"oneOf": [
+				{"properties": {"kind": {"const": "a"}, "first": {}, "second": {}}},
+				{"properties": {"kind": {"const": "b"}, "second": {}, "first": {}}}
+			]
+		}`)
+
+		fixed, err := FixBytes([]byte("first: 1\nkind: b\nsecond: 2\n"), ".yaml", unionSchemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+
+		expected := "kind: b\nsecond: 2\nfirst: 1\n"
+		if string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+	})
+
+	t.Run("Leaves ordered documents untouched", func(t *testing.T) {
+		content := []byte("name:    web   # keep formatting\nspec: {}\n")
+
+		fixed, err := FixBytes(content, ".yaml", schemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+		if string(fixed) != string(content) {
+			t.Errorf("FixBytes() changed an ordered document to %q", fixed)
+		}
+	})
+
+	t.Run("Header comment stays at the top", func(t *testing.T) {
+		content := []byte("# header\nspec: {}\nname: web\n")
+
+		expected := "# header\n# order: reordered to match schema position 1\nname: web\n# order: reordered to match schema position 2\nspec: {}\n"
+		fixed, err := FixBytes(content, ".yaml", schemaPath, FixOptions{Annotate: true})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+		if string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+
+		fixed, err = FixBytes([]byte("---\n# header\nspec: {}\nname: web\n"), ".yaml", schemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+		if expected := "---\n# header\nname: web\nspec: {}\n"; string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+
+		// A comment below another key documents the key it precedes, so it moves along
+		fixed, err = FixBytes([]byte("spec: {}\n# the name\nname: web\n"), ".yaml", schemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+		if expected := "# the name\nname: web\nspec: {}\n"; string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+	})
+
+	t.Run("Annotations are not stacked", func(t *testing.T) {
+		content := []byte("spec: {}\n# the name\nname: web\n")
+
+		fixed, err := FixBytes(content, ".yaml", schemaPath, FixOptions{Annotate: true})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+
+		expected := "# the name\n# order: reordered to match schema position 1\nname: web\n# order: reordered to match schema position 2\nspec: {}\n"
+		if string(fixed) != expected {
+			t.Fatalf("FixBytes() returned %q, expected %q", fixed, expected)
+		}
+
+		again, err := FixBytes(fixed, ".yaml", schemaPath, FixOptions{Annotate: true})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error on the second run: %v", err)
+		}
+		if string(again) != expected {
+			t.Errorf("FixBytes() returned %q on the second run, expected %q", again, expected)
+		}
+
+		// Moving a key again replaces its annotation
+		swapped := []byte("# order: reordered to match schema position 2\nspec: {}\n# the name\n# order: reordered to match schema position 1\nname: web\n")
+		fixed, err = FixBytes(swapped, ".yaml", schemaPath, FixOptions{Annotate: true})
+		if err != nil {
+			t.Fatalf("FixBytes() returned an error: %v", err)
+		}
+		if string(fixed) != expected {
+			t.Errorf("FixBytes() returned %q for an annotated document, expected %q", fixed, expected)
+		}
+	})
+}
+
+func TestFixReport(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+		"properties": {
+			"name": {},
+			"spec": {"properties": {"replicas": {}, "image": {}}}
+		}
+	}`)
+	original := "spec:\n  image: nginx\n  replicas: 2\nname: web\n"
+
+	t.Run("Reports moves without writing", func(t *testing.T) {
+		path := writeTestFile(t, tempDir, "dry.yaml", original)
+
+		report, err := FixReport(path, schemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixReport() returned an error: %v", err)
+		}
+
+		expected := []Move{
+			{Key: "name", From: 1, To: 0},
+			{Key: "spec", From: 0, To: 1},
+			{Path: []string{"spec"}, Key: "replicas", From: 1, To: 0},
+			{Path: []string{"spec"}, Key: "image", From: 0, To: 1},
+		}
+		if !reflect.DeepEqual(report.Moves, expected) {
+			t.Errorf("FixReport() returned moves %+v, expected %+v", report.Moves, expected)
+		}
+		if report.File != path || report.Schema != schemaPath {
+			t.Errorf("FixReport() returned unexpected report paths: %+v", report)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		if string(content) != original {
+			t.Errorf("FixReport() wrote the file without Write set: %q", content)
+		}
+	})
+
+	t.Run("Writes when asked", func(t *testing.T) {
+		path := writeTestFile(t, tempDir, "write.yaml", original)
+
+		report, err := FixReport(path, schemaPath, FixOptions{Write: true})
+		if err != nil {
+			t.Fatalf("FixReport() returned an error: %v", err)
+		}
+		if len(report.Moves) != 4 {
+			t.Errorf("FixReport() returned %d moves, expected 4", len(report.Moves))
+		}
+
+		if err := Lint(path, schemaPath); err != nil {
+			t.Errorf("Lint() returned an error after FixReport() wrote the file: %v", err)
+		}
+
+		report, err = FixReport(path, schemaPath, FixOptions{Write: true})
+		if err != nil || len(report.Moves) != 0 {
+			t.Errorf("FixReport() returned %+v, %v for an already fixed file", report, err)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// FixGlob reorders every YAML or JSON file matching pattern like FixReport, returning a report per matching file
+// in lexical order. Patterns use filepath.Match syntax per path element, and a ** element matches any number of
+// directories, as in configs/**/*.yaml. Files are only written under FixOptions.Write, and unchanged files never are
+func FixGlob(pattern, jsonSchemaPath string, opts FixOptions) ([]Report, error) {
+	schema, err := loadSchema(jsonSchemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := globFiles(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []Report
+	for _, path := range paths {
+		report, err := fixFile(path, jsonSchemaPath, schema, opts)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// WriteFixSummary writes the number of keys moved in every file of reports that changed, followed by
+// the totals, such as "2 of 5 files changed, 7 keys moved"
+func WriteFixSummary(w io.Writer, reports []Report) error {
+	changed, moved := 0, 0
+	for _, report := range reports {
+		if len(report.Moves) == 0 {
+			continue
+		}
+
+		changed++
+		moved += len(report.Moves)
+		if _, err := fmt.Fprintf(w, "%s: %s moved\n", report.File, pluralKeys(len(report.Moves))); err != nil {
+			return err
+		}
+	}
+
+	files := "files"
+	if len(reports) == 1 {
+		files = "file"
+	}
+	_, err := fmt.Fprintf(w, "%d of %d %s changed, %s moved\n", changed, len(reports), files, pluralKeys(moved))
+
+	return err
+}
+
+// pluralKeys formats a number of keys
+func pluralKeys(n int) string {
+	if n == 1 {
+		return "1 key"
+	}
+
+	return fmt.Sprintf("%d keys", n)
+}
+
+// globFiles returns the regular files matching pattern, where a ** element matches any number of directories,
+// in lexical order
+func globFiles(pattern string) ([]string, error) {
+	elements := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+
+	// Walk from the deepest directory the pattern names literally
+	base := 0
+	for base < len(elements)-1 && !strings.ContainsAny(elements[base], `*?[\`) {
+		base++
+	}
+	root := filepath.FromSlash(strings.Join(elements[:base], "/"))
+	if root == "" {
+		root = "."
+		if strings.HasPrefix(pattern, "/") {
+			root = "/"
+		}
+	}
+	elements = elements[base:]
+
+	// Bad patterns are reported even when nothing would be matched against them
+	for _, element := range elements {
+		if _, err := filepath.Match(element, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Like filepath.Glob, a missing directory matches nothing
+			if path == root && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if matchGlobElements(elements, strings.Split(filepath.ToSlash(rel), "/")) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// matchGlobElements reports whether the elements of a path match the elements of a pattern, ** matching
+// any number of them
+func matchGlobElements(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchGlobElements(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+	matched, _ := filepath.Match(pattern[0], path[0])
+
+	return matched && matchGlobElements(pattern[1:], path[1:])
+}
@@ -0,0 +1,92 @@
+package order
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFixGlob(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"name": {}, "spec": {"properties": {"replicas": {}, "image": {}}}}}`)
+	configsDir := filepath.Join(tempDir, "configs")
+	nestedDir := filepath.Join(configsDir, "prod", "eu")
+	if err := os.MkdirAll(nestedDir, 0o755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+
+	misorderedPath := writeTestFile(t, configsDir, "api.yaml", "spec:\n  image: nginx\n  replicas: 2\nname: api\n")
+	nestedPath := writeTestFile(t, nestedDir, "web.yaml", "spec: {}\nname: web\n")
+	orderedPath := writeTestFile(t, configsDir, "worker.yaml", "name: worker\n")
+	writeTestFile(t, configsDir, "notes.txt", "spec before name\n")
+
+	// Unchanged files must not be rewritten
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(orderedPath, past, past); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	pattern := filepath.Join(configsDir, "**", "*.yaml")
+
+	t.Run("Dry run", func(t *testing.T) {
+		reports, err := FixGlob(pattern, schemaPath, FixOptions{})
+		if err != nil {
+			t.Fatalf("FixGlob() returned an error: %v", err)
+		}
+
+		var b strings.Builder
+		if err := WriteFixSummary(&b, reports); err != nil {
+			t.Fatalf("WriteFixSummary() returned an error: %v", err)
+		}
+		expected := misorderedPath + ": 4 keys moved\n" + nestedPath + ": 2 keys moved\n2 of 3 files changed, 6 keys moved\n"
+		if b.String() != expected {
+			t.Errorf("WriteFixSummary() wrote %q, expected %q", b.String(), expected)
+		}
+
+		if err := Lint(misorderedPath, schemaPath); err == nil {
+			t.Errorf("FixGlob() wrote a file without Write set")
+		}
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		reports, err := FixGlob(pattern, schemaPath, FixOptions{Write: true})
+		if err != nil {
+			t.Fatalf("FixGlob() returned an error: %v", err)
+		}
+		if len(reports) != 3 {
+			t.Fatalf("FixGlob() returned %d reports, expected 3", len(reports))
+		}
+
+		for _, path := range []string{misorderedPath, nestedPath, orderedPath} {
+			if err := Lint(path, schemaPath); err != nil {
+				t.Errorf("Lint() returned an error after FixGlob() wrote the files: %v", err)
+			}
+		}
+
+		info, err := os.Stat(orderedPath)
+		if err != nil {
+			t.Fatalf("Failed to stat file: %v", err)
+		}
+		if !info.ModTime().Equal(past) {
+			t.Errorf("FixGlob() rewrote a file that was already in order")
+		}
+
+		entries, err := os.ReadDir(configsDir)
+		if err != nil {
+			t.Fatalf("Failed to read directory: %v", err)
+		}
+		if len(entries) != 4 {
+			t.Errorf("FixGlob() left temporary files behind: %v", entries)
+		}
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		reports, err := FixGlob(filepath.Join(tempDir, "missing", "*.yaml"), schemaPath, FixOptions{})
+		if err != nil || len(reports) != 0 {
+			t.Errorf("FixGlob() returned %v, %v for a missing directory", reports, err)
+		}
+	})
+}
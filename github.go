@@ -0,0 +1,64 @@
+package order
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteGitHubActions writes reports to w as GitHub Actions workflow commands, one ::error or ::warning line
+// per violation, which GitHub turns into annotations on the lines they name. Files without violations write nothing
+func WriteGitHubActions(w io.Writer, reports []Report) error {
+	for _, report := range reports {
+		for _, violation := range report.Violations {
+			// Leave source snippets out of the single-line message
+			violation.Snippet = ""
+
+			command := "error"
+			if violation.Severity == SeverityWarning {
+				command = "warning"
+			}
+			if err := writeWorkflowCommand(w, command, report.File, violation.Line, violation.Column, violation.Error()); err != nil {
+				return err
+			}
+		}
+
+		// Violations left out of a truncated report are noted next to the last one kept
+		if report.Truncated > 0 && len(report.Violations) > 0 {
+			last := report.Violations[len(report.Violations)-1]
+			if err := writeWorkflowCommand(w, "notice", report.File, last.Line, last.Column, truncatedMessage(report.Truncated)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeWorkflowCommand writes a single workflow command annotating file at line and column with message
+func writeWorkflowCommand(w io.Writer, command, file string, line, column int, message string) error {
+	properties := "file=" + escapeWorkflowProperty(file) + ",line=" + strconv.Itoa(line)
+	if column > 0 {
+		properties += ",col=" + strconv.Itoa(column)
+	}
+
+	_, err := fmt.Fprintf(w, "::%s %s::%s\n", command, properties, escapeWorkflowData(message))
+	return err
+}
+
+// workflowDataEscaper escapes the characters ending the message of a workflow command
+var workflowDataEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// workflowPropertyEscaper escapes the characters ending a property of a workflow command as well
+var workflowPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// escapeWorkflowData escapes message for use as the message of a workflow command
+func escapeWorkflowData(message string) string {
+	return workflowDataEscaper.Replace(message)
+}
+
+// escapeWorkflowProperty escapes value for use as a property of a workflow command
+func escapeWorkflowProperty(value string) string {
+	return workflowPropertyEscaper.Replace(value)
+}
@@ -0,0 +1,51 @@
+package order
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteGitHubActions(t *testing.T) {
+	reports := []Report{
+		{
+			File: "config.yaml",
+			Violations: []Violation{
+				{
+					Path:    []string{"dependencies"},
+					Key:     "development",
+					Line:    7,
+					Column:  3,
+					Message: "properties out of order: 'development' should come after 'production' according to the schema",
+				},
+				{
+					Key:      "legacy",
+					Line:     9,
+					Column:   1,
+					Message:  "deprecated property 'legacy'",
+					Severity: SeverityWarning,
+					Snippet:  "> 9 | legacy: true",
+				},
+			},
+		},
+		{
+			File:       "dir,with:odd%name.yaml",
+			Violations: []Violation{{Key: "b", Line: 2, Column: 1, Message: "100% wrong\nproperties out of order"}},
+			Truncated:  12,
+		},
+		{File: "valid.yaml"},
+	}
+
+	var b strings.Builder
+	if err := WriteGitHubActions(&b, reports); err != nil {
+		t.Fatalf("WriteGitHubActions() returned an error: %v", err)
+	}
+
+	expected := `::error file=config.yaml,line=7,col=3::in property 'dependencies': properties out of order: 'development' should come after 'production' according to the schema
+::warning file=config.yaml,line=9,col=1::deprecated property 'legacy'
+::error file=dir%2Cwith%3Aodd%25name.yaml,line=2,col=1::100%25 wrong%0Aproperties out of order
+::notice file=dir%2Cwith%3Aodd%25name.yaml,line=2,col=1::... and 12 more
+`
+	if b.String() != expected {
+		t.Errorf("WriteGitHubActions() wrote:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+}
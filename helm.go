@@ -0,0 +1,27 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LintHelmValues validates that the values.yaml of the Helm chart in chartDir follows the property order of the
+// values.schema.json the chart ships, which is a standard JSON schema. Missing files are reported naming the chart
+func LintHelmValues(chartDir string) error {
+	valuesPath := filepath.Join(chartDir, "values.yaml")
+	schemaPath := filepath.Join(chartDir, "values.schema.json")
+
+	for _, path := range []string{valuesPath, schemaPath} {
+		if _, err := os.Stat(path); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("chart %s has no %s: %w", chartDir, filepath.Base(path), fs.ErrNotExist)
+			}
+			return err
+		}
+	}
+
+	return LintWithOptions(valuesPath, schemaPath, LintOptions{})
+}
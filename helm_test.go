@@ -0,0 +1,59 @@
+package order
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestLintHelmValues(t *testing.T) {
+	schema := `{
+  "$schema": "https://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "replicaCount": {"type": "integer"},
+    "image": {
+      "type": "object",
+      "properties": {"repository": {"type": "string"}, "tag": {"type": "string"}}
+    }
+  }
+}`
+
+	t.Run("Values in schema order", func(t *testing.T) {
+		chartDir := t.TempDir()
+		writeTestFile(t, chartDir, "values.schema.json", schema)
+		writeTestFile(t, chartDir, "values.yaml", "replicaCount: 1\nimage:\n  repository: nginx\n  tag: latest\n")
+
+		if err := LintHelmValues(chartDir); err != nil {
+			t.Errorf("LintHelmValues() returned an error for values in schema order: %v", err)
+		}
+	})
+
+	t.Run("Values out of order", func(t *testing.T) {
+		chartDir := t.TempDir()
+		writeTestFile(t, chartDir, "values.schema.json", schema)
+		writeTestFile(t, chartDir, "values.yaml", "replicaCount: 1\nimage:\n  tag: latest\n  repository: nginx\n")
+
+		err := LintHelmValues(chartDir)
+		var violation *Violation
+		if !errors.As(err, &violation) || violation.Key != "tag" || violation.Line != 3 {
+			t.Errorf("LintHelmValues() returned unexpected error for values out of order: %v", err)
+		}
+	})
+
+	for _, missing := range []string{"values.yaml", "values.schema.json"} {
+		t.Run("Missing "+missing, func(t *testing.T) {
+			chartDir := t.TempDir()
+			for _, name := range []string{"values.yaml", "values.schema.json"} {
+				if name != missing {
+					writeTestFile(t, chartDir, name, schema)
+				}
+			}
+
+			err := LintHelmValues(chartDir)
+			if !errors.Is(err, fs.ErrNotExist) || err.Error() != "chart "+chartDir+" has no "+missing+": file does not exist" {
+				t.Errorf("LintHelmValues() returned unexpected error for a chart without %s: %v", missing, err)
+			}
+		})
+	}
+}
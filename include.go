@@ -0,0 +1,70 @@
+package order
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeTag is the YAML tag of scalars naming a document to inline under LintOptions.Include
+const includeTag = "!include"
+
+// resolveIncludes replaces every node of root tagged !include with the document the include callback returns for
+// the path it names, resolving the includes of that document too. The inlined nodes take the position of the
+// !include tag, so violations found in them are reported where the document includes them
+func resolveIncludes(root *yaml.Node, include func(path string) ([]byte, error)) error {
+	return resolveIncludesIn(root, include, make(map[string]bool))
+}
+
+// resolveIncludesIn resolves the includes of node and its descendants, with including holding the paths being inlined
+func resolveIncludesIn(node *yaml.Node, include func(path string) ([]byte, error), including map[string]bool) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == includeTag {
+		path := node.Value
+		if including[path] {
+			return fmt.Errorf("circular !include of %q at line %d, column %d", path, node.Line, node.Column)
+		}
+
+		content, err := include(path)
+		if err != nil {
+			return fmt.Errorf("!include %q at line %d, column %d: %w", path, node.Line, node.Column, err)
+		}
+
+		var document yaml.Node
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return fmt.Errorf("!include %q at line %d, column %d: %w", path, node.Line, node.Column, err)
+		}
+
+		// An empty document includes null
+		included := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		if len(document.Content) > 0 {
+			included = document.Content[0]
+		}
+
+		including[path] = true
+		err = resolveIncludesIn(included, include, including)
+		delete(including, path)
+		if err != nil {
+			return err
+		}
+
+		moveNodes(included, node.Line, node.Column)
+		*node = *included
+		return nil
+	}
+
+	for _, child := range node.Content {
+		if err := resolveIncludesIn(child, include, including); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveNodes sets the position of node and its descendants to the given line and column
+func moveNodes(node *yaml.Node, line, column int) {
+	node.Line, node.Column = line, column
+	for _, child := range node.Content {
+		moveNodes(child, line, column)
+	}
+}
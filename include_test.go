@@ -0,0 +1,71 @@
+package order
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintIncludes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+  "properties": {
+    "name": {},
+    "spec": {
+      "properties": {
+        "replicas": {},
+        "containers": {"items": {"properties": {"name": {}, "image": {}}}}
+      }
+    }
+  }
+}`)
+	writeTestFile(t, tempDir, "spec.yaml", "replicas: 2\ncontainers:\n  - !include container.yaml\n")
+	writeTestFile(t, tempDir, "container.yaml", "image: nginx\nname: web\n")
+	writeTestFile(t, tempDir, "loop.yaml", "replicas: !include loop.yaml\n")
+	documentPath := writeTestFile(t, tempDir, "service.yaml", "name: web\nspec: !include spec.yaml\n")
+
+	include := func(path string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(tempDir, path))
+	}
+
+	t.Run("Included keys are validated at the include site", func(t *testing.T) {
+		violations, err := lintAll(documentPath, schemaPath, LintOptions{Include: include})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+
+		expected := "in property 'spec': in property 'containers[0]': properties out of order: 'image' should come after 'name' according to the schema"
+		if len(violations) != 1 || violations[0].Error() != expected || violations[0].Line != 2 || violations[0].Column != 7 {
+			t.Errorf("LintAll() returned unexpected violations for included keys out of order: %+v", violations)
+		}
+
+		err = LintWithOptions(documentPath, schemaPath, LintOptions{Include: include, Cache: NewMemoryCache()})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("LintWithOptions() returned unexpected error for included keys out of order: %v", err)
+		}
+	})
+
+	t.Run("Includes are left alone without Include", func(t *testing.T) {
+		if err := Lint(documentPath, schemaPath); err != nil {
+			t.Errorf("Lint() returned an error for a document with includes: %v", err)
+		}
+	})
+
+	t.Run("Include errors", func(t *testing.T) {
+		missingPath := writeTestFile(t, tempDir, "missing.yaml", "name: web\nspec: !include absent.yaml\n")
+		err := LintWithOptions(missingPath, schemaPath, LintOptions{Include: include})
+		if !errors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), `!include "absent.yaml" at line 2, column 7`) {
+			t.Errorf("LintWithOptions() returned unexpected error for a missing include: %v", err)
+		}
+
+		loopPath := writeTestFile(t, tempDir, "looping.yaml", "spec: !include loop.yaml\n")
+		_, err = lintAll(loopPath, schemaPath, LintOptions{Include: include})
+		if err == nil || !strings.Contains(err.Error(), `circular !include of "loop.yaml" at line 1, column 11`) {
+			t.Errorf("LintAll() returned unexpected error for a circular include: %v", err)
+		}
+	})
+}
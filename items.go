@@ -0,0 +1,46 @@
+package order
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkSortedItems reports the first element of the sequence node, found at path, that sorts after the element
+// following it, returning whether validation should continue. Sequences holding anything but scalars aren't checked
+func (v *validator) checkSortedItems(node *yaml.Node, path []string) bool {
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode {
+			return true
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i++ {
+		item, next := node.Content[i], node.Content[i+1]
+		if v.itemLess(next, item) {
+			return v.report(path, item,
+				"items out of order: '"+item.Value+"' should come after '"+next.Value+"' in '"+path[len(path)-1]+"'")
+		}
+	}
+
+	return true
+}
+
+// itemLess reports whether scalar node a sorts before b. Numbers compare by value, other scalars like keys
+// under LintOptions.Sort
+func (v *validator) itemLess(a, b *yaml.Node) bool {
+	if isNumberTag(a.ShortTag()) && isNumberTag(b.ShortTag()) {
+		numberA, errA := strconv.ParseFloat(a.Value, 64)
+		numberB, errB := strconv.ParseFloat(b.Value, 64)
+		if errA == nil && errB == nil {
+			return numberA < numberB
+		}
+	}
+
+	return v.opts.Sort.less(a.Value, b.Value)
+}
+
+// isNumberTag reports whether tag is the tag of YAML integers or floats
+func isNumberTag(tag string) bool {
+	return tag == "!!int" || tag == "!!float"
+}
@@ -0,0 +1,83 @@
+package order
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLintSortedItems(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+  "properties": {
+    "cors": {
+      "properties": {
+        "allowedOrigins": {"type": "array", "x-sort-items": true},
+        "ports": {"type": "array", "x-sort-items": true},
+        "rules": {"type": "array", "x-sort-items": true}
+      }
+    },
+    "tags": {"type": "array"}
+  }
+}`)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name: "Sorted items",
+			content: `cors:
+  allowedOrigins: [https://a.example, https://b.example]
+  ports: [9, 10, 443]
+tags: [z, a]
+`,
+		},
+		{
+			name: "Items out of order",
+			content: `cors:
+  allowedOrigins:
+    - https://a.example
+    - https://c.example
+    - https://b.example
+  ports: [443, 80]
+`,
+			expected: []string{
+				"4:7: items out of order: 'https://c.example' should come after 'https://b.example' in 'allowedOrigins'",
+				"6:11: items out of order: '443' should come after '80' in 'ports'",
+			},
+		},
+		{
+			name: "Arrays of objects aren't checked",
+			content: `cors:
+  rules:
+    - name: b
+    - name: a
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documentPath := writeTestFile(t, tempDir, "document.yaml", tt.content)
+
+			violations, err := lintAll(documentPath, schemaPath, LintOptions{})
+			if err != nil {
+				t.Fatalf("LintAll() returned an error: %v", err)
+			}
+
+			var got []string
+			for _, violation := range violations {
+				got = append(got, fmt.Sprintf("%d:%d: %s", violation.Line, violation.Column, violation.Message))
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("LintAll() returned %q, expected %q", got, tt.expected)
+			}
+			if len(violations) > 0 && !reflect.DeepEqual(violations[0].Path, []string{"cors", "allowedOrigins"}) {
+				t.Errorf("LintAll() returned path %v, expected the path of the array", violations[0].Path)
+			}
+		})
+	}
+}
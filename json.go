@@ -0,0 +1,316 @@
+package order
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonDecoder wraps a json.Decoder with the content being decoded so tokens can be mapped back to
+// their line and column, giving JSON nodes the same position information YAML nodes carry
+type jsonDecoder struct {
+	*json.Decoder
+	content    []byte
+	lineStarts []int
+
+	// The last position computed, from which columns further along the same line are counted,
+	// so minified documents holding a single long line aren't rescanned for every token
+	lastOffset, lastLine, lastColumn int
+}
+
+// newJSONDecoder creates a jsonDecoder over content
+func newJSONDecoder(content []byte) *jsonDecoder {
+	lineStarts := []int{0}
+	for i, b := range content {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	// Numbers keep their text, so values written back or compared aren't reformatted
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	return &jsonDecoder{
+		Decoder:    decoder,
+		content:    content,
+		lineStarts: lineStarts,
+	}
+}
+
+// position returns the 1-based line and column of the given byte offset
+func (d *jsonDecoder) position(offset int) (int, int) {
+	line := sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > offset }) - 1
+
+	var column int
+	if line+1 == d.lastLine && offset >= d.lastOffset {
+		column = d.lastColumn + utf8.RuneCount(d.content[d.lastOffset:offset])
+	} else {
+		column = utf8.RuneCount(d.content[d.lineStarts[line]:offset]) + 1
+	}
+	d.lastOffset, d.lastLine, d.lastColumn = offset, line+1, column
+
+	return line + 1, column
+}
+
+// tokenPosition returns the line and column where the most recently returned token starts
+func (d *jsonDecoder) tokenPosition() (int, int) {
+	end := int(d.InputOffset())
+	if end > len(d.content) {
+		end = len(d.content)
+	}
+	start := end - 1
+
+	switch {
+	case start < 0:
+		start = 0
+	case d.content[start] == '"':
+		// Walk back to the opening quote, skipping escaped quotes
+		for start--; start > 0; start-- {
+			if d.content[start] != '"' {
+				continue
+			}
+
+			backslashes := 0
+			for i := start - 1; i >= 0 && d.content[i] == '\\'; i-- {
+				backslashes++
+			}
+			if backslashes%2 == 0 {
+				break
+			}
+		}
+	case bytes.IndexByte([]byte("{}[]"), d.content[start]) >= 0:
+		// Delimiters are a single byte
+	default:
+		// Walk back to the start of a number, boolean or null literal
+		for start > 0 && bytes.IndexByte([]byte(" \t\r\n:,[{"), d.content[start-1]) < 0 {
+			start--
+		}
+	}
+
+	return d.position(start)
+}
+
+// errorf returns an error located at the start of the most recently returned token
+func (d *jsonDecoder) errorf(format string, args ...any) error {
+	line, column := d.tokenPosition()
+	return fmt.Errorf(format+" at line %d, column %d", append(args, line, column)...)
+}
+
+// locateError adds the line and column to syntax errors of the underlying decoder,
+// which only report a byte offset, and to errors caused by input ending early
+func (d *jsonDecoder) locateError(err error) error {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset counts the bytes read up to and including the invalid one
+		line, column := d.position(max(int(syntaxErr.Offset)-1, 0))
+		return fmt.Errorf("%w at line %d, column %d", err, line, column)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		line, column := d.position(len(d.content))
+		return fmt.Errorf("unexpected end of JSON input at line %d, column %d: %w", line, column, io.ErrUnexpectedEOF)
+	}
+
+	return err
+}
+
+// parseJSONWithOrder parses JSON content while preserving property order
+func parseJSONWithOrder(r io.Reader) (*yaml.Node, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a document node as the root
+	doc := &yaml.Node{
+		Kind:   yaml.DocumentNode,
+		Line:   1,
+		Column: 1,
+	}
+
+	// Parse the JSON content, an object or an array of them
+	decoder := newJSONDecoder(content)
+	t, err := decoder.Token()
+	if err != nil {
+		return nil, decoder.locateError(err)
+	}
+
+	var value *yaml.Node
+	switch t {
+	case json.Delim('{'):
+		value, err = parseJSONObjectBody(decoder)
+	case json.Delim('['):
+		value, err = parseJSONArray(decoder)
+	default:
+		return nil, decoder.errorf("expected JSON object or array")
+	}
+	if err != nil {
+		return nil, decoder.locateError(err)
+	}
+
+	// Add the parsed value as content of the document
+	doc.Content = append(doc.Content, value)
+
+	return doc, nil
+}
+
+// parseJSONObjectBody parses the key-value pairs of a JSON object whose opening brace has already been consumed
+func parseJSONObjectBody(decoder *jsonDecoder) (*yaml.Node, error) {
+	// Create a mapping node for the object
+	line, column := decoder.tokenPosition()
+	obj := &yaml.Node{
+		Kind:   yaml.MappingNode,
+		Tag:    "!!map",
+		Line:   line,
+		Column: column,
+	}
+
+	// Parse key-value pairs
+	for {
+		// Read the next token, which should be a key or closing brace
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		// Check if we've reached the end of the object
+		if t == json.Delim('}') {
+			break
+		}
+
+		// Get the key name
+		key, ok := t.(string)
+		if !ok {
+			return nil, decoder.errorf("expected string key in JSON object")
+		}
+
+		// Create a scalar node for the key
+		line, column := decoder.tokenPosition()
+		keyNode := &yaml.Node{
+			Kind:   yaml.ScalarNode,
+			Tag:    "!!str",
+			Value:  key,
+			Line:   line,
+			Column: column,
+		}
+
+		// Parse the value
+		valueNode, err := parseJSONValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		// Add the key-value pair to the mapping
+		obj.Content = append(obj.Content, keyNode, valueNode)
+	}
+
+	return obj, nil
+}
+
+// parseJSONValue parses a JSON value into a YAML node
+func parseJSONValue(decoder *jsonDecoder) (*yaml.Node, error) {
+	t, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJSONToken(decoder, t)
+}
+
+// parseJSONToken converts an already consumed token, and for objects and arrays everything up to
+// their closing delimiter, into a YAML node
+func parseJSONToken(decoder *jsonDecoder, t json.Token) (*yaml.Node, error) {
+	line, column := decoder.tokenPosition()
+	scalar := func(tag, value string) *yaml.Node {
+		return &yaml.Node{
+			Kind:   yaml.ScalarNode,
+			Tag:    tag,
+			Value:  value,
+			Line:   line,
+			Column: column,
+		}
+	}
+
+	switch v := t.(type) {
+	case string:
+		return scalar("!!str", v), nil
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			return scalar("!!float", string(v)), nil
+		}
+		return scalar("!!int", string(v)), nil
+	case bool:
+		value, _ := jsonScalarValue(v)
+		return scalar("!!bool", value), nil
+	case nil:
+		return scalar("!!null", "null"), nil
+	case json.Delim:
+		if v == '{' {
+			return parseJSONObjectBody(decoder)
+		} else if v == '[' {
+			return parseJSONArray(decoder)
+		}
+		return nil, decoder.errorf("unexpected JSON delimiter")
+	}
+
+	return nil, decoder.errorf("unexpected JSON value")
+}
+
+// jsonScalarValue formats a scalar JSON token the way it is stored in the Value of a YAML node, numbers keeping
+// their text, reporting false for delimiters
+func jsonScalarValue(t json.Token) (string, bool) {
+	switch v := t.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return string(v), true
+	case bool:
+		return fmt.Sprintf("%t", v), true
+	case nil:
+		return "null", true
+	}
+
+	return "", false
+}
+
+// parseJSONArray parses a JSON array, whose opening bracket has already been consumed, into a YAML sequence node
+func parseJSONArray(decoder *jsonDecoder) (*yaml.Node, error) {
+	// Create a sequence node for the array
+	line, column := decoder.tokenPosition()
+	arr := &yaml.Node{
+		Kind:   yaml.SequenceNode,
+		Tag:    "!!seq",
+		Line:   line,
+		Column: column,
+	}
+
+	// Parse array elements
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		// Check if we've reached the end of the array
+		if t == json.Delim(']') {
+			break
+		}
+
+		valueNode, err := parseJSONToken(decoder, t)
+		if err != nil {
+			return nil, err
+		}
+
+		// Add the value to the array
+		arr.Content = append(arr.Content, valueNode)
+	}
+
+	return arr, nil
+}
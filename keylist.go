@@ -0,0 +1,301 @@
+package order
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LintAgainstKeyList validates a YAML or JSON file against a key list instead of a JSON schema.
+// A key list is a YAML or JSON array of key names in their expected order, where an array following a key
+// lists the order of that key's own children, e.g. ["name", "spec", ["replicas", "image"], "status"]
+func LintAgainstKeyList(yamlOrJsonPath, keyListPath string) error {
+	schema, err := loadKeyList(keyListPath)
+	if err != nil {
+		return err
+	}
+
+	content, root, err := parseDocument(yamlOrJsonPath, LintOptions{})
+	if err != nil {
+		return withPath(yamlOrJsonPath, err)
+	}
+
+	err = firstViolation(content, root, schema, LintOptions{})
+	setViolationPaths(err, yamlOrJsonPath, keyListPath, "")
+
+	return withPath(yamlOrJsonPath, err)
+}
+
+// loadKeyList reads the key list at path into a nameless root property
+func loadKeyList(path string) (*SchemaProperty, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, withPath(path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, fmt.Errorf("%s: expected key list to be an array", path)
+	}
+
+	properties, err := parseKeyList(root.Content[0])
+	if err != nil {
+		return nil, withPath(path, err)
+	}
+
+	return &SchemaProperty{Properties: properties}, nil
+}
+
+// parseKeyList builds the properties listed by a key list sequence node, nested sequences giving the children
+// of the key before them
+func parseKeyList(node *yaml.Node) ([]*SchemaProperty, error) {
+	if node.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("expected key list to be an array at line %d, column %d", node.Line, node.Column)
+	}
+
+	var properties []*SchemaProperty
+	for _, item := range node.Content {
+		switch item.Kind {
+		case yaml.ScalarNode:
+			properties = append(properties, &SchemaProperty{Name: item.Value})
+		case yaml.SequenceNode:
+			if len(properties) == 0 || len(properties[len(properties)-1].Properties) > 0 {
+				return nil, fmt.Errorf("expected nested key list to follow a key at line %d, column %d", item.Line, item.Column)
+			}
+
+			children, err := parseKeyList(item)
+			if err != nil {
+				return nil, err
+			}
+			properties[len(properties)-1].Properties = children
+		default:
+			return nil, fmt.Errorf("expected key list entry to be a string or an array at line %d, column %d", item.Line, item.Column)
+		}
+	}
+
+	return properties, nil
+}
+
+// NewSchema builds a property tree from a spec written in code, following the key list format: a []any or []string
+// of names in their expected order, where a []any or []string right after a name lists that name's children,
+// e.g. []any{"name", "spec", []any{"replicas", "image"}, "status"}. The tree can be used with LintAgainstProperties.
+// It errors on a malformed spec, naming the position of the offending entry
+func NewSchema(spec any) ([]*SchemaProperty, error) {
+	return newSchemaProperties(spec, "")
+}
+
+// MustNewSchema is like NewSchema but panics on a malformed spec, which is a programming error for a spec
+// written in code
+func MustNewSchema(spec any) []*SchemaProperty {
+	properties, err := NewSchema(spec)
+	if err != nil {
+		panic("order: MustNewSchema: " + err.Error())
+	}
+
+	return properties
+}
+
+// newSchemaProperties builds the properties listed by spec, found at path, nested lists giving the children
+// of the name before them
+func newSchemaProperties(spec any, path string) ([]*SchemaProperty, error) {
+	var entries []any
+	switch spec := spec.(type) {
+	case []any:
+		entries = spec
+	case []string:
+		for _, name := range spec {
+			entries = append(entries, name)
+		}
+	default:
+		return nil, fmt.Errorf("expected %s to be a []any or []string, got %T", specPath(path), spec)
+	}
+
+	var properties []*SchemaProperty
+	for i, entry := range entries {
+		entryPath := path + "[" + strconv.Itoa(i) + "]"
+		switch entry := entry.(type) {
+		case string:
+			properties = append(properties, &SchemaProperty{Name: entry})
+		case []any, []string:
+			if len(properties) == 0 || len(properties[len(properties)-1].Properties) > 0 {
+				return nil, fmt.Errorf("expected nested list at %s to follow a name", specPath(entryPath))
+			}
+
+			parent := properties[len(properties)-1]
+			children, err := newSchemaProperties(entry, entryPath)
+			if err != nil {
+				return nil, err
+			}
+			parent.Properties = children
+		default:
+			return nil, fmt.Errorf("expected %s to be a string or a nested list, got %T", specPath(entryPath), entry)
+		}
+	}
+
+	return properties, nil
+}
+
+// specPath names the position path of a NewSchema spec in errors
+func specPath(path string) string {
+	if path == "" {
+		return "spec"
+	}
+
+	return "spec" + path
+}
+
+// LintKeysOrdered validates that the given keys keep their relative order in every mapping of a YAML or JSON file
+// holding several of them, at any level. Every other key is ignored, so no schema is needed
+func LintKeysOrdered(yamlOrJsonPath string, keys []string) error {
+	_, root, err := parseDocument(yamlOrJsonPath, LintOptions{})
+	if err != nil {
+		return withPath(yamlOrJsonPath, err)
+	}
+
+	positions := make(map[string]int, len(keys))
+	for i, key := range keys {
+		if _, ok := positions[key]; !ok {
+			positions[key] = i
+		}
+	}
+
+	for _, node := range root.Content {
+		if violation := checkKeysOrdered(node, positions, nil); violation != nil {
+			setViolationPaths(violation, yamlOrJsonPath, "", "")
+			return withPath(yamlOrJsonPath, violation)
+		}
+	}
+
+	return nil
+}
+
+// checkKeysOrdered returns the first key of the mappings in node, found at path, preceding a key it should follow
+// according to positions, or nil when the listed keys are in order everywhere
+func checkKeysOrdered(node *yaml.Node, positions map[string]int, path []string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if keyNode, laterNode := keysOutOfOrder(node, positions); keyNode != nil {
+			violation := newViolation(keyNode,
+				"properties out of order: '"+keyNode.Value+"' should come after '"+laterNode.Value+
+					"' according to the key order")
+			violation.Path = path
+			return violation
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := checkKeysOrdered(node.Content[i+1], positions, appendPath(path, node.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := checkKeysOrdered(item, positions, indexPath(path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// keysOutOfOrder returns the first key of the mapping node listed in positions that precedes a listed key it should
+// follow, along with that later key, or nils when the listed keys are in order
+func keysOutOfOrder(node *yaml.Node, positions map[string]int) (*yaml.Node, *yaml.Node) {
+	var keyNodes []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if _, ok := positions[node.Content[i].Value]; ok {
+			keyNodes = append(keyNodes, node.Content[i])
+		}
+	}
+
+	for i, keyNode := range keyNodes {
+		for _, laterNode := range keyNodes[i+1:] {
+			if positions[keyNode.Value] > positions[laterNode.Value] {
+				return keyNode, laterNode
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// LintSiblingsConsistent validates that sibling objects of a YAML or JSON file write their keys in the same order,
+// without a schema. The objects of an array, or the values of a mapping holding only objects, follow the key order
+// of the first of them. Keys missing from either object are ignored, so optional keys don't count as deviations
+func LintSiblingsConsistent(yamlOrJsonPath string) error {
+	_, root, err := parseDocument(yamlOrJsonPath, LintOptions{})
+	if err != nil {
+		return withPath(yamlOrJsonPath, err)
+	}
+
+	for _, node := range root.Content {
+		if violation := checkSiblingsConsistent(node, nil); violation != nil {
+			setViolationPaths(violation, yamlOrJsonPath, "", "")
+			return withPath(yamlOrJsonPath, violation)
+		}
+	}
+
+	return nil
+}
+
+// checkSiblingsConsistent returns the first key of a sibling object in node, found at path, that deviates from the
+// key order of the first sibling, or nil when all siblings are consistent
+func checkSiblingsConsistent(node *yaml.Node, path []string) error {
+	var siblings []*yaml.Node
+	var siblingPaths [][]string
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			siblings = append(siblings, node.Content[i+1])
+			siblingPaths = append(siblingPaths, appendPath(path, node.Content[i].Value))
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			siblings = append(siblings, item)
+			siblingPaths = append(siblingPaths, indexPath(path, i))
+		}
+	}
+
+	var objects []int
+	for i, sibling := range siblings {
+		if sibling.Kind == yaml.MappingNode {
+			objects = append(objects, i)
+		}
+	}
+
+	// A mapping only groups similar objects when all of its values are objects
+	if len(objects) > 1 && (node.Kind == yaml.SequenceNode || len(objects) == len(siblings)) {
+		reference := siblings[objects[0]]
+		referencePath := siblingPaths[objects[0]]
+
+		positions := make(map[string]int, len(reference.Content)/2)
+		for i := 0; i+1 < len(reference.Content); i += 2 {
+			if _, ok := positions[reference.Content[i].Value]; !ok {
+				positions[reference.Content[i].Value] = i / 2
+			}
+		}
+
+		for _, i := range objects[1:] {
+			if keyNode, laterNode := keysOutOfOrder(siblings[i], positions); keyNode != nil {
+				violation := newViolation(keyNode,
+					"properties out of order: '"+keyNode.Value+"' should come after '"+laterNode.Value+
+						"' as in '"+referencePath[len(referencePath)-1]+"'")
+				violation.Path = siblingPaths[i]
+				return violation
+			}
+		}
+	}
+
+	for i, sibling := range siblings {
+		if err := checkSiblingsConsistent(sibling, siblingPaths[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
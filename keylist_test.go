@@ -0,0 +1,256 @@
+package order
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLintAgainstKeyList(t *testing.T) {
+	tempDir := t.TempDir()
+
+	yamlKeyListPath := writeTestFile(t, tempDir, "keys.yaml", `- name
+- spec
+- - replicas
+  - image
+- status
+`)
+	jsonKeyListPath := writeTestFile(t, tempDir, "keys.json", `["name", "spec", ["replicas", "image"], "status"]`)
+
+	for _, keyListPath := range []string{yamlKeyListPath, jsonKeyListPath} {
+		t.Run("Document in order against "+keyListPath[len(tempDir)+1:], func(t *testing.T) {
+			validPath := writeTestFile(t, tempDir, "valid.yaml", "name: web\nspec:\n  replicas: 2\n  image: nginx\nstatus: {}\n")
+
+			err := LintAgainstKeyList(validPath, keyListPath)
+			if err != nil {
+				t.Errorf("LintAgainstKeyList() returned an error for a document in order: %v", err)
+			}
+		})
+
+		t.Run("Nested keys out of order against "+keyListPath[len(tempDir)+1:], func(t *testing.T) {
+			invalidPath := writeTestFile(t, tempDir, "invalid.yaml", "name: web\nspec:\n  image: nginx\n  replicas: 2\n")
+
+			err := LintAgainstKeyList(invalidPath, keyListPath)
+			if err == nil {
+				t.Errorf("LintAgainstKeyList() did not return an error for nested keys out of order")
+			} else if !strings.Contains(err.Error(), "in property 'spec'") || !strings.Contains(err.Error(), "'image' should come after 'replicas'") {
+				t.Errorf("LintAgainstKeyList() returned unexpected error: %v", err)
+			}
+		})
+	}
+
+	t.Run("Invalid key lists", func(t *testing.T) {
+		validPath := writeTestFile(t, tempDir, "doc.yaml", "name: web\n")
+
+		tests := []struct {
+			name     string
+			content  string
+			expected string
+		}{
+			{"Not an array", `{"name": {}}`, "expected key list to be an array at line 1, column 1"},
+			{"Nested list first", `[["name"]]`, "expected nested key list to follow a key at line 1, column 2"},
+			{"Two nested lists", `["spec", ["a"], ["b"]]`, "expected nested key list to follow a key at line 1, column 17"},
+			{"Object entry", `["name", {"spec": []}]`, "expected key list entry to be a string or an array at line 1, column 10"},
+		}
+
+		for _, tt := range tests {
+			keyListPath := writeTestFile(t, tempDir, "invalid.json", tt.content)
+
+			err := LintAgainstKeyList(validPath, keyListPath)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("%s: LintAgainstKeyList() returned %v, expected an error containing %q", tt.name, err, tt.expected)
+			}
+		}
+	})
+}
+
+func TestLintKeysOrdered(t *testing.T) {
+	tempDir := t.TempDir()
+
+	keys := []string{"name", "image", "command"}
+
+	t.Run("Listed keys in order", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "valid.yaml", `version: 1
+name: web
+containers:
+  - ports: [80]
+    name: app
+    env: {}
+    image: nginx
+  - command: [run]
+    other: x
+image: base
+`)
+		if err := LintKeysOrdered(documentPath, keys); err != nil {
+			t.Errorf("LintKeysOrdered() returned an error for listed keys in order: %v", err)
+		}
+	})
+
+	t.Run("Listed keys out of order", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "invalid.json", `{
+  "name": "web",
+  "containers": [
+    {"name": "app", "image": "nginx"},
+    {"command": ["run"], "env": {}, "name": "job"}
+  ]
+}`)
+
+		err := LintKeysOrdered(documentPath, keys)
+		var violation *Violation
+		if !errors.As(err, &violation) {
+			t.Fatalf("LintKeysOrdered() did not return a violation for listed keys out of order: %v", err)
+		}
+
+		expected := "in property 'containers[1]': properties out of order: 'command' should come after 'name' according to the key order"
+		if violation.Error() != expected || violation.Line != 5 || violation.File != documentPath {
+			t.Errorf("LintKeysOrdered() returned %q at line %d, expected %q at line 5", violation.Error(), violation.Line, expected)
+		}
+	})
+}
+
+func TestLintSiblingsConsistent(t *testing.T) {
+	tempDir := t.TempDir()
+
+	t.Run("Consistent siblings", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "valid.yaml", `users:
+  - name: alice
+    email: alice@example.com
+    roles: [admin]
+  - name: bob
+    roles: []
+  - email: carol@example.com
+    nickname: c
+servers:
+  web: {host: a, port: 80}
+  db: {host: b, port: 5432}
+metadata: {port: 1, host: x}
+`)
+		if err := LintSiblingsConsistent(documentPath); err != nil {
+			t.Errorf("LintSiblingsConsistent() returned an error for consistent siblings: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name         string
+		content      string
+		expected     string
+		expectedLine int
+	}{
+		{
+			name: "Array item deviating",
+			content: `users:
+  - name: alice
+    email: alice@example.com
+  - name: bob
+    email: bob@example.com
+  - email: carol@example.com
+    name: carol
+`,
+			expected:     "in property 'users[2]': properties out of order: 'email' should come after 'name' as in 'users[0]'",
+			expectedLine: 6,
+		},
+		{
+			name: "Mapping value deviating",
+			content: `servers:
+  web:
+    host: a
+    port: 80
+  db:
+    port: 5432
+    host: b
+`,
+			expected:     "in property 'servers': in property 'db': properties out of order: 'port' should come after 'host' as in 'web'",
+			expectedLine: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documentPath := writeTestFile(t, tempDir, "invalid.yaml", tt.content)
+
+			err := LintSiblingsConsistent(documentPath)
+			var violation *Violation
+			if !errors.As(err, &violation) {
+				t.Fatalf("LintSiblingsConsistent() did not return a violation for a deviating sibling: %v", err)
+			}
+
+			if violation.Error() != tt.expected || violation.Line != tt.expectedLine {
+				t.Errorf("LintSiblingsConsistent() returned %q at line %d, expected %q at line %d",
+					violation.Error(), violation.Line, tt.expected, tt.expectedLine)
+			}
+		})
+	}
+}
+
+func TestNewSchema(t *testing.T) {
+	t.Run("Nested lists", func(t *testing.T) {
+		got, err := NewSchema([]any{"name", "spec", []any{"replicas", "template", []string{"image", "command"}}, "status"})
+		if err != nil {
+			t.Fatalf("NewSchema() returned an error: %v", err)
+		}
+
+		expected := []*SchemaProperty{
+			{Name: "name"},
+			{Name: "spec", Properties: []*SchemaProperty{
+				{Name: "replicas"},
+				{Name: "template", Properties: []*SchemaProperty{{Name: "image"}, {Name: "command"}}},
+			}},
+			{Name: "status"},
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("NewSchema() returned an unexpected tree: %s", dumpTree(t, got))
+		}
+	})
+
+	t.Run("Linting against the tree", func(t *testing.T) {
+		tempDir := t.TempDir()
+		path := writeTestFile(t, tempDir, "config.yaml", "name: web\nspec:\n  template: {}\n  replicas: 2\n")
+
+		err := LintAgainstProperties(path, MustNewSchema([]any{"name", "spec", []string{"replicas", "template"}}), LintOptions{})
+		var violation *Violation
+		if !errors.As(err, &violation) || violation.Key != "template" {
+			t.Errorf("LintAgainstProperties() returned unexpected error for a document out of order: %v", err)
+		}
+	})
+
+	malformed := []struct {
+		name     string
+		spec     any
+		expected string
+	}{
+		{name: "Not a list", spec: "name", expected: "expected spec to be a []any or []string, got string"},
+		{name: "Nested list first", spec: []any{[]any{"a"}}, expected: "expected nested list at spec[0] to follow a name"},
+		{name: "Two nested lists", spec: []any{"a", []any{"b"}, []any{"c"}}, expected: "expected nested list at spec[2] to follow a name"},
+		{name: "Unexpected entry", spec: []any{"a", []any{"b", 1}}, expected: "expected spec[1][1] to be a string or a nested list, got int"},
+	}
+
+	for _, tt := range malformed {
+		t.Run(tt.name, func(t *testing.T) {
+			properties, err := NewSchema(tt.spec)
+			if err == nil || err.Error() != tt.expected || properties != nil {
+				t.Errorf("NewSchema() returned %v, %v, expected the error %q", properties, err, tt.expected)
+			}
+
+			defer func() {
+				if r := recover(); r != "order: MustNewSchema: "+tt.expected {
+					t.Errorf("MustNewSchema() panicked with %v, expected %q", r, "order: MustNewSchema: "+tt.expected)
+				}
+			}()
+
+			MustNewSchema(tt.spec)
+		})
+	}
+}
+
+// dumpTree renders properties with DumpSchema for failure messages
+func dumpTree(t *testing.T, properties []*SchemaProperty) string {
+	t.Helper()
+
+	var b strings.Builder
+	if err := DumpSchema(&b, properties); err != nil {
+		t.Fatalf("DumpSchema() returned an error: %v", err)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package order
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkKeyOrderRegex reports every key of node and its descendants matching LintOptions.KeyOrderRegex whose captured
+// value sorts after the value of a later matching key of the same mapping, returning whether validation should continue
+func (v *validator) checkKeyOrderRegex(node *yaml.Node, path []string) bool {
+	switch node.Kind {
+	case yaml.MappingNode:
+		var keyNodes []*yaml.Node
+		var values []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if value, ok := v.capturedOrderValue(node.Content[i].Value); ok {
+				keyNodes = append(keyNodes, node.Content[i])
+				values = append(values, value)
+			}
+		}
+
+		for i := range keyNodes {
+			for j := i + 1; j < len(keyNodes); j++ {
+				if compareCaptured(values[j], values[i]) < 0 {
+					if !v.report(path, keyNodes[i],
+						"properties out of order: '"+keyNodes[i].Value+"' ("+values[i]+") should come after '"+
+							keyNodes[j].Value+"' ("+values[j]+") according to the key order pattern") {
+						return false
+					}
+					break
+				}
+			}
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if !v.checkKeyOrderRegex(node.Content[i+1], appendPath(path, node.Content[i].Value)) {
+				return false
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if !v.checkKeyOrderRegex(item, indexPath(path, i)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// capturedOrderValue returns the substring of key captured by group LintOptions.CaptureGroup of
+// LintOptions.KeyOrderRegex, reporting false when the key doesn't match or the group didn't take part in the match
+func (v *validator) capturedOrderValue(key string) (string, bool) {
+	match := v.opts.KeyOrderRegex.FindStringSubmatchIndex(key)
+	group := v.opts.CaptureGroup
+	if match == nil || 2*group+1 >= len(match) || match[2*group] < 0 {
+		return "", false
+	}
+
+	return key[match[2*group]:match[2*group+1]], true
+}
+
+// compareCaptured compares two captured values numerically when both are numbers, lexically otherwise
+func compareCaptured(a, b string) int {
+	numberA, errA := strconv.ParseFloat(a, 64)
+	numberB, errB := strconv.ParseFloat(b, 64)
+	switch {
+	case errA != nil || errB != nil:
+		return strings.Compare(a, b)
+	case numberA < numberB:
+		return -1
+	case numberA > numberB:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,67 @@
+package order
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestLintKeyOrderRegex(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"name": {}, "releases": {}}}`)
+	opts := LintOptions{KeyOrderRegex: regexp.MustCompile(`^release-(\d+)$`), CaptureGroup: 1}
+
+	t.Run("Keys in captured order", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "valid.yaml", `name: app
+releases:
+  release-9: {}
+  notes: {}
+  release-10: {}
+  release-100:
+    release-2: {}
+    release-3: {}
+`)
+		if err := LintWithOptions(documentPath, schemaPath, opts); err != nil {
+			t.Errorf("LintWithOptions() returned an error for keys in captured order: %v", err)
+		}
+	})
+
+	t.Run("Keys out of captured order", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "invalid.yaml", `name: app
+releases:
+  release-10: {}
+  release-9: {}
+  release-11:
+    release-3: {}
+    release-2: {}
+`)
+		violations, err := lintAll(documentPath, schemaPath, opts)
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+
+		expected := []string{
+			"in property 'releases': properties out of order: 'release-10' (10) should come after 'release-9' (9) according to the key order pattern",
+			"in property 'releases': in property 'release-11': properties out of order: 'release-3' (3) should come after 'release-2' (2) according to the key order pattern",
+		}
+		if len(violations) != len(expected) {
+			t.Fatalf("LintAll() returned %d violations, expected %d: %+v", len(violations), len(expected), violations)
+		}
+		for i, violation := range violations {
+			if violation.Error() != expected[i] {
+				t.Errorf("LintAll() returned %q, expected %q", violation.Error(), expected[i])
+			}
+		}
+		if violations[0].Line != 3 {
+			t.Errorf("LintAll() reported line %d, expected 3", violations[0].Line)
+		}
+	})
+
+	t.Run("Lexical captures", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "lexical.yaml", "releases:\n  build-beta: {}\n  build-alpha: {}\n")
+		err := LintWithOptions(documentPath, schemaPath, LintOptions{KeyOrderRegex: regexp.MustCompile(`^build-\w+$`)})
+		if err == nil {
+			t.Errorf("LintWithOptions() did not return an error for keys out of lexical order of the whole match")
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package order
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubernetesOrder is the conventional order of the top-level fields of a Kubernetes manifest
+var kubernetesOrder = []string{"apiVersion", "kind", "metadata", "spec", "status"}
+
+// LintKubernetes validates that a Kubernetes manifest lists its top-level fields in the conventional
+// apiVersion, kind, metadata, spec, status order without requiring a schema file.
+// Every document of a multi-document YAML file is checked, the first violation of each failing document being
+// joined with errors.Join. When the file holds more than one document, messages name the document number, starting at 1
+func LintKubernetes(path string) error {
+	schema := &SchemaProperty{}
+	for _, name := range kubernetesOrder {
+		schema.Properties = append(schema.Properties, &SchemaProperty{Name: name})
+	}
+
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		content, root, err := parseDocument(path, LintOptions{})
+		if err != nil {
+			return withPath(path, err)
+		}
+
+		err = firstViolation(content, root, schema, LintOptions{})
+		setViolationPaths(err, path, "", "")
+
+		return withPath(path, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var documents []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return withPath(path, err)
+		}
+		documents = append(documents, &document)
+	}
+
+	var errs []error
+	for i, document := range documents {
+		err := firstViolation(content, document, schema, LintOptions{})
+		var violation *Violation
+		if len(documents) > 1 && errors.As(err, &violation) {
+			violation.Message = "document " + strconv.Itoa(i+1) + ": " + violation.Message
+		}
+		if err != nil {
+			setViolationPaths(err, path, "", "")
+			errs = append(errs, withPath(path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// StrategicMergePatchDirectives lists the prefixes of the directive keys of strategic merge patches, such as the
+// Kustomize patch fragments holding $patch: delete. Passing it as LintOptions.IgnorePrefixes keeps the directives
+// out of every order check, including the sorting of objects marked "x-order": "alphabetical"
+var StrategicMergePatchDirectives = []string{"$patch", "$retainKeys", "$setElementOrder/", "$deleteFromPrimitiveList/"}
+
+// LintDocumentOrder validates that the documents of a multi-document YAML file, such as a bundle of manifests, appear
+// in the given order of their discriminator values, e.g. Namespace before Deployment for the discriminator kind.
+// Documents whose discriminator is missing or isn't listed in order are ignored
+func LintDocumentOrder(path string, order []string, discriminator string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	positions := make(map[string]int, len(order))
+	for i, value := range order {
+		if _, ok := positions[value]; !ok {
+			positions[value] = i
+		}
+	}
+
+	// The discriminator keys of the listed documents, along with their document numbers
+	var keyNodes []*yaml.Node
+	var values []string
+	var numbers []int
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for number := 1; ; number++ {
+		var document yaml.Node
+		if err := decoder.Decode(&document); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return withPath(path, err)
+		}
+
+		if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		node := document.Content[0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value != discriminator || node.Content[i+1].Kind != yaml.ScalarNode {
+				continue
+			}
+			if _, ok := positions[node.Content[i+1].Value]; ok {
+				keyNodes = append(keyNodes, node.Content[i])
+				values = append(values, node.Content[i+1].Value)
+				numbers = append(numbers, number)
+			}
+			break
+		}
+	}
+
+	for i := range keyNodes {
+		for j := i + 1; j < len(keyNodes); j++ {
+			if positions[values[i]] > positions[values[j]] {
+				violation := newViolation(keyNodes[i],
+					"documents out of order: document "+strconv.Itoa(numbers[i])+" with "+discriminator+" '"+values[i]+
+						"' should come after document "+strconv.Itoa(numbers[j])+" with "+discriminator+" '"+values[j]+"'")
+				setViolationPaths(violation, path, "", "")
+				return withPath(path, violation)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package order
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLintKubernetes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	t.Run("Conventional manifest", func(t *testing.T) {
+		validPath := writeTestFile(t, tempDir, "deployment.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 2
+status: {}
+`)
+
+		err := LintKubernetes(validPath)
+		if err != nil {
+			t.Errorf("LintKubernetes() returned an error for a conventional manifest: %v", err)
+		}
+	})
+
+	t.Run("Kind before apiVersion", func(t *testing.T) {
+		invalidPath := writeTestFile(t, tempDir, "service.yaml", `kind: Service
+apiVersion: v1
+metadata:
+  name: web
+spec:
+  type: ClusterIP
+`)
+
+		err := LintKubernetes(invalidPath)
+		expected := invalidPath + ": properties out of order: 'kind' should come after 'apiVersion' according to the schema"
+		if err == nil {
+			t.Errorf("LintKubernetes() did not return an error for kind before apiVersion")
+		} else if err.Error() != expected {
+			t.Errorf("LintKubernetes() returned %q, expected %q without a document number", err, expected)
+		}
+	})
+
+	t.Run("Unknown top-level fields are ignored", func(t *testing.T) {
+		validPath := writeTestFile(t, tempDir, "configmap.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  key: value
+`)
+
+		err := LintKubernetes(validPath)
+		if err != nil {
+			t.Errorf("LintKubernetes() returned an error for a manifest with extra fields: %v", err)
+		}
+	})
+
+	t.Run("Every document of a bundle", func(t *testing.T) {
+		bundlePath := writeTestFile(t, tempDir, "bundle.yaml", `apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+---
+apiVersion: v1
+metadata:
+  name: web
+kind: Service
+---
+kind: ConfigMap
+apiVersion: v1
+`)
+
+		err := LintKubernetes(bundlePath)
+		if err == nil {
+			t.Fatalf("LintKubernetes() did not return an error for documents out of order")
+		}
+
+		expected := bundlePath + ": document 2: properties out of order: 'metadata' should come after 'kind' according to the schema\n" +
+			bundlePath + ": document 3: properties out of order: 'kind' should come after 'apiVersion' according to the schema"
+		if err.Error() != expected {
+			t.Errorf("LintKubernetes() returned %q, expected %q", err, expected)
+		}
+
+		var violation *Violation
+		if !errors.As(err, &violation) || violation.Line != 7 || violation.File != bundlePath {
+			t.Errorf("LintKubernetes() returned unexpected first violation: %+v", violation)
+		}
+	})
+}
+
+func TestLintStrategicMergePatches(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "deployment.schema.json", `{
+  "properties": {
+    "apiVersion": {}, "kind": {}, "metadata": {"properties": {"name": {}, "labels": {"x-order": "alphabetical"}}},
+    "spec": {"properties": {"replicas": {}, "template": {"properties": {"spec": {"properties": {
+      "containers": {"items": {"properties": {"name": {}, "image": {}, "env": {}}}},
+      "volumes": {}
+    }}}}}}
+  }
+}`)
+	patch := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+    tier: frontend
+    $patch: replace
+spec:
+  template:
+    spec:
+      $setElementOrder/containers:
+        - name: web
+      containers:
+        - name: web
+          image: nginx:1.27
+        - name: sidecar
+          $patch: delete
+      $retainKeys: [containers]
+`
+	patchPath := writeTestFile(t, tempDir, "patch.yaml", patch)
+	opts := LintOptions{IgnorePrefixes: StrategicMergePatchDirectives}
+
+	t.Run("Patch in order", func(t *testing.T) {
+		if err := LintWithOptions(patchPath, schemaPath, opts); err != nil {
+			t.Errorf("LintWithOptions() returned an error for a patch in order: %v", err)
+		}
+
+		// Without the directives ignored, $patch breaks the alphabetical order of the labels
+		err := LintWithOptions(patchPath, schemaPath, LintOptions{})
+		if err == nil || !strings.Contains(err.Error(), "should come after '$patch' alphabetically") {
+			t.Errorf("LintWithOptions() returned unexpected error for directives among sorted keys: %v", err)
+		}
+	})
+
+	t.Run("Patch out of order", func(t *testing.T) {
+		misorderedPath := writeTestFile(t, tempDir, "misordered.yaml", strings.Replace(patch,
+			"        - name: web\n          image: nginx:1.27\n", "        - image: nginx:1.27\n          name: web\n", 1))
+
+		violations, err := lintAll(misorderedPath, schemaPath, opts)
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+		if len(violations) != 1 || violations[0].Key != "image" || violations[0].Line != 15 {
+			t.Errorf("LintAll() returned unexpected violations for a patch out of order: %+v", violations)
+		}
+	})
+}
+
+func TestLintDocumentOrder(t *testing.T) {
+	tempDir := t.TempDir()
+
+	kinds := []string{"Namespace", "ConfigMap", "Deployment", "Service"}
+
+	t.Run("Documents in order", func(t *testing.T) {
+		bundlePath := writeTestFile(t, tempDir, "valid.yaml", `apiVersion: v1
+kind: Namespace
+---
+# Comment-only and unlisted documents are ignored
+---
+kind: CustomResourceDefinition
+---
+kind: Deployment
+---
+metadata: {name: no-kind}
+---
+kind: Service
+---
+kind: Service
+`)
+		if err := LintDocumentOrder(bundlePath, kinds, "kind"); err != nil {
+			t.Errorf("LintDocumentOrder() returned an error for documents in order: %v", err)
+		}
+	})
+
+	t.Run("Documents out of order", func(t *testing.T) {
+		bundlePath := writeTestFile(t, tempDir, "invalid.yaml", `apiVersion: apps/v1
+kind: Deployment
+---
+apiVersion: v1
+kind: Service
+---
+apiVersion: v1
+kind: Namespace
+`)
+
+		err := LintDocumentOrder(bundlePath, kinds, "kind")
+		var violation *Violation
+		if !errors.As(err, &violation) {
+			t.Fatalf("LintDocumentOrder() did not return a violation for documents out of order: %v", err)
+		}
+
+		expected := "documents out of order: document 1 with kind 'Deployment' should come after document 3 with kind 'Namespace'"
+		if violation.Error() != expected || violation.Line != 2 || violation.File != bundlePath {
+			t.Errorf("LintDocumentOrder() returned %q at line %d, expected %q at line 2", violation.Error(), violation.Line, expected)
+		}
+	})
+
+	t.Run("Invalid document", func(t *testing.T) {
+		bundlePath := writeTestFile(t, tempDir, "broken.yaml", "kind: Namespace\n---\nkind: [\n")
+		err := LintDocumentOrder(bundlePath, kinds, "kind")
+		if err == nil || !strings.HasPrefix(err.Error(), bundlePath) {
+			t.Errorf("LintDocumentOrder() returned unexpected error for an invalid document: %v", err)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package order
+
+import "fmt"
+
+// LintBestMatch lints a YAML or JSON file against every schema of schemas, for files whose schema isn't known,
+// returning the path of the schema it matches best. The best schema is the one recognizing the most keys of the
+// document, the keys checked against one of its properties, then the one the document has the fewest violations
+// of, warnings aside, then the first listed. Keys are counted first as a schema recognizing few of them
+// trivially has few violations. The error is a *LintResult holding the violations of the best schema,
+// nil when the document passes it, or the error of a document or schema that couldn't be read
+func LintBestMatch(yamlOrJsonPath string, schemas []string) (string, error) {
+	var best *LintResult
+	bestSchema := ""
+	bestFailures, bestRecognized := 0, 0
+
+	for _, jsonSchemaPath := range schemas {
+		recognized := 0
+		opts := LintOptions{checked: func(string) {
+			recognized++
+		}}
+
+		result, err := LintAll(yamlOrJsonPath, jsonSchemaPath, opts)
+		if err != nil {
+			return "", err
+		}
+
+		failures := 0
+		for _, violation := range result.Violations {
+			if violation.Severity != SeverityWarning {
+				failures++
+			}
+		}
+
+		if best == nil || recognized > bestRecognized || recognized == bestRecognized && failures < bestFailures {
+			best, bestSchema = result, jsonSchemaPath
+			bestFailures, bestRecognized = failures, recognized
+		}
+	}
+
+	if best == nil {
+		return "", fmt.Errorf("%s: no schema to match", yamlOrJsonPath)
+	}
+	if best.OK() {
+		return bestSchema, nil
+	}
+
+	return bestSchema, best
+}
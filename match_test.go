@@ -0,0 +1,64 @@
+package order
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLintBestMatch(t *testing.T) {
+	tempDir := t.TempDir()
+
+	serviceSchema := writeTestFile(t, tempDir, "service.json", `{"properties": {"name": {}, "port": {}, "replicas": {}}}`)
+	jobSchema := writeTestFile(t, tempDir, "job.json", `{"properties": {"name": {}, "schedule": {}, "command": {}}}`)
+	reversedSchema := writeTestFile(t, tempDir, "reversed.json", `{"properties": {"replicas": {}, "port": {}, "name": {}}}`)
+
+	servicePath := writeTestFile(t, tempDir, "service.yaml", "name: web\nport: 80\nreplicas: 2\n")
+	jobPath := writeTestFile(t, tempDir, "job.yaml", "name: backup\nschedule: daily\ncommand: tar\n")
+	misplacedPath := writeTestFile(t, tempDir, "misplaced.yaml", "schedule: daily\nname: backup\ncommand: tar\n")
+	unknownPath := writeTestFile(t, tempDir, "unknown.yaml", "kind: Secret\n")
+
+	t.Run("Fewest violations", func(t *testing.T) {
+		schema, err := LintBestMatch(servicePath, []string{reversedSchema, serviceSchema, jobSchema})
+		if err != nil {
+			t.Errorf("LintBestMatch() returned an error for a document matching a schema: %v", err)
+		}
+		if schema != serviceSchema {
+			t.Errorf("LintBestMatch() returned %s, expected %s", schema, serviceSchema)
+		}
+	})
+
+	t.Run("Most keys recognized", func(t *testing.T) {
+		schema, err := LintBestMatch(jobPath, []string{serviceSchema, jobSchema})
+		if err != nil {
+			t.Errorf("LintBestMatch() returned an error for a document matching a schema: %v", err)
+		}
+		if schema != jobSchema {
+			t.Errorf("LintBestMatch() returned %s, expected %s", schema, jobSchema)
+		}
+	})
+
+	t.Run("Violations of the best schema", func(t *testing.T) {
+		schema, err := LintBestMatch(misplacedPath, []string{serviceSchema, jobSchema})
+		if schema != jobSchema {
+			t.Errorf("LintBestMatch() returned %s, expected %s", schema, jobSchema)
+		}
+
+		var result *LintResult
+		if !errors.As(err, &result) || len(result.Violations) != 1 || result.Violations[0].Key != "schedule" {
+			t.Errorf("LintBestMatch() returned unexpected violations of the best schema: %v", err)
+		}
+	})
+
+	t.Run("No key recognized", func(t *testing.T) {
+		schema, err := LintBestMatch(unknownPath, []string{serviceSchema, jobSchema})
+		if err != nil || schema != serviceSchema {
+			t.Errorf("LintBestMatch() returned %s, %v, expected the first schema", schema, err)
+		}
+	})
+
+	t.Run("Unreadable schema", func(t *testing.T) {
+		if _, err := LintBestMatch(servicePath, []string{serviceSchema, "missing.json"}); err == nil {
+			t.Errorf("LintBestMatch() returned no error for a missing schema")
+		}
+	})
+}
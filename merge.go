@@ -0,0 +1,62 @@
+package order
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// isMergeKey reports whether keyNode is a YAML merge key, an unquoted << merging the mappings of its value
+func isMergeKey(keyNode *yaml.Node) bool {
+	return keyNode.Kind == yaml.ScalarNode && keyNode.Tag == "!!merge"
+}
+
+// mergeSources returns the mappings merged by the value of a merge key, either a single mapping or a sequence
+// of them, usually aliases of anchored defaults
+func mergeSources(valueNode *yaml.Node) []*yaml.Node {
+	valueNode = resolveAlias(valueNode)
+
+	var sources []*yaml.Node
+	switch valueNode.Kind {
+	case yaml.MappingNode:
+		sources = append(sources, valueNode)
+	case yaml.SequenceNode:
+		for _, item := range valueNode.Content {
+			if item = resolveAlias(item); item.Kind == yaml.MappingNode {
+				sources = append(sources, item)
+			}
+		}
+	}
+
+	return sources
+}
+
+// mergedKeys gathers the keys the merge keys of a mapping node bring in, including those merged recursively
+// by the merged mappings themselves
+func mergedKeys(node *yaml.Node) map[string]bool {
+	keys := make(map[string]bool)
+
+	var collect func(node *yaml.Node, seen map[*yaml.Node]bool)
+	collect = func(node *yaml.Node, seen map[*yaml.Node]bool) {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if !isMergeKey(node.Content[i]) {
+				continue
+			}
+
+			for _, source := range mergeSources(node.Content[i+1]) {
+				if seen[source] {
+					continue
+				}
+				seen[source] = true
+
+				for j := 0; j+1 < len(source.Content); j += 2 {
+					if !isMergeKey(source.Content[j]) {
+						keys[source.Content[j].Value] = true
+					}
+				}
+				collect(source, seen)
+			}
+		}
+	}
+	collect(node, make(map[*yaml.Node]bool))
+
+	return keys
+}
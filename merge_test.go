@@ -0,0 +1,92 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLintMergeKeys(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+  "properties": {
+    "build": {"$ref": "#/definitions/job"},
+    "test": {"$ref": "#/definitions/job"}
+  },
+  "definitions": {
+    "job": {"properties": {"stage": {}, "image": {}, "retries": {}, "script": {}}}
+  }
+}`)
+	pipelinePath := writeTestFile(t, tempDir, "pipeline.yaml", `.defaults: &defaults
+  image: alpine
+  script: [echo]
+  retries: 2
+.tools: &tools
+  script: [lint]
+  image: golang
+build:
+  <<: *defaults
+  stage: build
+  script: [make]
+test:
+  <<: [*defaults, *tools]
+  stage: test
+`)
+
+	t.Run("Explicit keys are checked among themselves", func(t *testing.T) {
+		err := LintWithOptions(pipelinePath, schemaPath, LintOptions{Exact: true, IgnorePrefixes: []string{"."}})
+		if err != nil {
+			t.Errorf("LintWithOptions() returned an error for keys written after a merge: %v", err)
+		}
+
+		overridePath := writeTestFile(t, tempDir, "override.yaml", "build:\n  <<: {image: alpine}\n  script: [make]\n  stage: build\n")
+		violations, err := lintAll(overridePath, schemaPath, LintOptions{})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+		if len(violations) != 1 || violations[0].Key != "script" || violations[0].Line != 3 {
+			t.Errorf("LintAll() returned unexpected violations for overrides out of order: %+v", violations)
+		}
+	})
+
+	t.Run("Merged keys follow their anchor order", func(t *testing.T) {
+		violations, err := lintAll(pipelinePath, schemaPath, LintOptions{CheckMergedKeys: true})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+
+		// Defaults merged by both jobs are only reported once, at their anchor
+		var got []string
+		var lines []int
+		for _, violation := range violations {
+			got = append(got, violation.Error())
+			lines = append(lines, violation.Line)
+		}
+		expected := []string{
+			"in property 'build': properties out of order: 'script' should come after 'retries' according to the schema",
+			"in property 'test': properties out of order: 'script' should come after 'image' according to the schema",
+		}
+		if !reflect.DeepEqual(got, expected) || !reflect.DeepEqual(lines, []int{3, 6}) {
+			t.Errorf("LintAll() returned %q at lines %v, expected %q at lines 3 and 6", got, lines, expected)
+		}
+
+		violations, err = lintAll(pipelinePath, schemaPath, LintOptions{})
+		if err != nil || len(violations) != 0 {
+			t.Errorf("LintAll() checked merged keys without CheckMergedKeys: %v, %v", violations, err)
+		}
+	})
+
+	t.Run("Merge sources", func(t *testing.T) {
+		var root yaml.Node
+		if err := yaml.Unmarshal([]byte("a: &a {x: 1}\nb: &b {<<: *a, y: 2}\nc: {<<: [*b], z: 3}\n"), &root); err != nil {
+			t.Fatalf("Failed to parse test document: %v", err)
+		}
+
+		keys := mergedKeys(root.Content[0].Content[5])
+		if !reflect.DeepEqual(keys, map[string]bool{"x": true, "y": true}) {
+			t.Errorf("mergedKeys() returned %v, expected x and y", keys)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package order
+
+import "sort"
+
+// MinimalMoves returns the fewest keys of a mapping, given in document order, that must move to bring it into the
+// order of schema, along with their indexes among keys before and after the fix. The keys forming the longest
+// subsequence already in schema order stay put and every other key of the schema moves, even one whose index
+// doesn't change as keys around it move. The final order is the one Fix produces, where keys missing from
+// the schema keep their index. Moves are listed by From and have no Path
+func MinimalMoves(keys []string, schema []*SchemaProperty) []Move {
+	positions := make(map[string]int, len(schema))
+	for i, prop := range schema {
+		if _, ok := positions[prop.Name]; !ok {
+			positions[prop.Name] = i
+		}
+	}
+
+	// Only keys known to the schema move, sharing the slots they occupy between them
+	var slots []int
+	for i, key := range keys {
+		if _, ok := positions[key]; ok {
+			slots = append(slots, i)
+		}
+	}
+
+	sorted := append([]int(nil), slots...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return positions[keys[sorted[i]]] < positions[keys[sorted[j]]]
+	})
+	target := make(map[int]int, len(slots))
+	for i, slot := range slots {
+		target[sorted[i]] = slot
+	}
+
+	stays := longestOrderedRun(keys, slots, positions)
+
+	var moves []Move
+	for _, slot := range slots {
+		if !stays[slot] {
+			moves = append(moves, Move{Key: keys[slot], From: slot, To: target[slot]})
+		}
+	}
+
+	return moves
+}
+
+// longestOrderedRun returns the indexes of the longest subsequence of the keys at slots whose schema positions
+// never decrease, found by patience sorting
+func longestOrderedRun(keys []string, slots []int, positions map[string]int) map[int]bool {
+	// tails[n] is the index into slots of the smallest last key of the subsequences of length n+1 found so far
+	var tails []int
+	previous := make([]int, len(slots))
+	for i, slot := range slots {
+		position := positions[keys[slot]]
+		n := sort.Search(len(tails), func(n int) bool {
+			return positions[keys[slots[tails[n]]]] > position
+		})
+
+		previous[i] = -1
+		if n > 0 {
+			previous[i] = tails[n-1]
+		}
+		if n == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[n] = i
+		}
+	}
+
+	stays := make(map[int]bool, len(tails))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = previous[i] {
+			stays[slots[i]] = true
+		}
+	}
+
+	return stays
+}
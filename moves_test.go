@@ -0,0 +1,70 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMinimalMoves(t *testing.T) {
+	schema := []*SchemaProperty{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+
+	tests := []struct {
+		name     string
+		keys     []string
+		expected []Move
+	}{
+		{
+			name: "Keys in order",
+			keys: []string{"a", "b", "d", "e"},
+		},
+		{
+			name:     "Last key belongs first",
+			keys:     []string{"b", "c", "d", "a"},
+			expected: []Move{{Key: "a", From: 3, To: 0}},
+		},
+		{
+			name:     "First key belongs last",
+			keys:     []string{"d", "a", "b", "c"},
+			expected: []Move{{Key: "d", From: 0, To: 3}},
+		},
+		{
+			name:     "Two swapped neighbours",
+			keys:     []string{"a", "c", "b", "d"},
+			expected: []Move{{Key: "c", From: 1, To: 2}},
+		},
+		{
+			name:     "Unknown keys keep their index",
+			keys:     []string{"a", "x", "c", "b", "y"},
+			expected: []Move{{Key: "c", From: 2, To: 3}},
+		},
+		{
+			name: "Reversed keys",
+			keys: []string{"e", "d", "c", "b", "a"},
+			expected: []Move{
+				{Key: "e", From: 0, To: 4},
+				{Key: "d", From: 1, To: 3},
+				{Key: "c", From: 2, To: 2},
+				{Key: "b", From: 3, To: 1},
+			},
+		},
+		{
+			name:     "Two runs",
+			keys:     []string{"c", "d", "e", "a", "b"},
+			expected: []Move{{Key: "a", From: 3, To: 0}, {Key: "b", From: 4, To: 1}},
+		},
+		{
+			name:     "Duplicate keys",
+			keys:     []string{"b", "a", "b"},
+			expected: []Move{{Key: "b", From: 0, To: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moves := MinimalMoves(tt.keys, schema)
+			if !reflect.DeepEqual(moves, tt.expected) {
+				t.Errorf("MinimalMoves() returned %+v, expected %+v", moves, tt.expected)
+			}
+		})
+	}
+}
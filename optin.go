@@ -0,0 +1,43 @@
+package order
+
+import (
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// optedIn reports whether the document opts into validation under LintOptions.RequireOptInKey, its root mapping
+// holding key, or one of its comments starting with it, such as "# order-schema: strict" for "order-schema"
+func optedIn(root *yaml.Node, key string) bool {
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 && root.Content[0].Kind == yaml.MappingNode {
+		mapping := root.Content[0]
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == key {
+				return true
+			}
+		}
+	}
+
+	return hasOptInComment(root, key)
+}
+
+// hasOptInComment reports whether a comment of node or of the nodes below it starts with key
+func hasOptInComment(node *yaml.Node, key string) bool {
+	for _, comment := range []string{node.HeadComment, node.LineComment, node.FootComment} {
+		for _, line := range strings.Split(comment, "\n") {
+			text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+			if rest, ok := strings.CutPrefix(text, key); ok && (rest == "" || rest[0] == ':' || unicode.IsSpace(rune(rest[0]))) {
+				return true
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		if hasOptInComment(child, key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,61 @@
+package order
+
+import (
+	"testing"
+)
+
+func TestLintRequireOptInKey(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"name": {}, "version": {}}}`)
+	keyPath := writeTestFile(t, tempDir, "key.yaml", "x-order-enforced: true\nversion: 1.0.0\nname: web\n")
+	commentPath := writeTestFile(t, tempDir, "comment.yaml", "# order-schema: strict\nversion: 1.0.0\nname: web\n")
+	otherPath := writeTestFile(t, tempDir, "other.yaml", "# order-schema-draft\nversion: 1.0.0\nname: web\n")
+
+	tests := []struct {
+		name     string
+		path     string
+		key      string
+		expected bool
+	}{
+		{name: "Opted in with a key", path: keyPath, key: "x-order-enforced", expected: true},
+		{name: "Opted in with a comment", path: commentPath, key: "order-schema", expected: true},
+		{name: "Not opted in", path: otherPath, key: "order-schema", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := LintOptions{RequireOptInKey: tt.key}
+
+			err := LintWithOptions(tt.path, schemaPath, opts)
+			if tt.expected && err == nil {
+				t.Errorf("LintWithOptions() returned no error for a document opted in")
+			}
+			if !tt.expected && err != nil {
+				t.Errorf("LintWithOptions() returned an error for a document not opted in: %v", err)
+			}
+
+			result, err := LintAll(tt.path, schemaPath, opts)
+			if err != nil {
+				t.Fatalf("LintAll() returned an error: %v", err)
+			}
+			if result.Skipped == tt.expected {
+				t.Errorf("LintAll() returned Skipped %v, expected %v", result.Skipped, !tt.expected)
+			}
+
+			report, err := LintReport(tt.path, schemaPath, opts)
+			if err != nil {
+				t.Fatalf("LintReport() returned an error: %v", err)
+			}
+			if report.Skipped == tt.expected || report.Skipped != (len(report.Violations) == 0) {
+				t.Errorf("LintReport() returned Skipped %v with %d violations", report.Skipped, len(report.Violations))
+			}
+		})
+	}
+
+	t.Run("Every document checked by default", func(t *testing.T) {
+		if err := Lint(otherPath, schemaPath); err == nil {
+			t.Errorf("Lint() returned no error for a document out of order")
+		}
+	})
+}
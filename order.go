@@ -1,12 +1,20 @@
 package order
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,270 +23,2152 @@ import (
 type SchemaProperty struct {
 	Name       string
 	Properties []*SchemaProperty
+
+	// OrderConstraints holds the [before, after] pairs declared with x-order-constraints.
+	// When present they replace the total order given by Properties with a partial order
+	OrderConstraints [][2]string
+
+	// Const holds the scalar value the schema pins the property to with const, checked under LintOptions.CheckConst.
+	// Numbers keep the text they have in the schema
+	Const *string
+
+	// Order holds the x-order annotation. OrderAlphabetical requires the keys of the object to be sorted,
+	// which is useful for free-form maps whose keys can't be listed in the schema
+	Order string
+
+	// Section holds the x-section name grouping the property with its siblings of the same section.
+	// Once a document leaves a section its keys can't reappear
+	Section string
+
+	// Required is set when the parent schema lists the property in its required array
+	Required bool
+
+	// Items holds the schema of the elements of an array property, declared with items
+	Items *SchemaProperty
+
+	// SortItems is set by x-sort-items: true, requiring the scalar elements of an array property, such as an
+	// allowlist, to be in ascending order
+	SortItems bool
+
+	// Deprecated is set when the schema marks the property with deprecated: true, reported under LintOptions.WarnDeprecated
+	Deprecated bool
+
+	// OneOf holds the branches of a oneOf. They are only used when Discriminator names the property
+	// telling which branch an object follows
+	OneOf []*SchemaProperty
+
+	// Discriminator holds the discriminator propertyName, whose value in the document selects the OneOf branch
+	// an object is validated against
+	Discriminator string
+
+	// DiscriminatorValues lists the discriminator values selecting this OneOf branch. They come from the
+	// discriminator mapping, the const of the discriminator property or the name of the schema the branch refers to
+	DiscriminatorValues []string
+
+	// ref holds the $ref the schema was resolved from
+	ref string
 }
 
-// Lint validates that a YAML or JSON file follows the property order specified in a JSON schema
+// OrderAlphabetical is the x-order value requiring an object's keys to be sorted lexically
+const OrderAlphabetical = "alphabetical"
+
+// LintOptions configures optional behaviour of LintWithOptions
+type LintOptions struct {
+	// MaxDepth limits validation to the given number of nesting levels, the root mapping being level 1.
+	// Properties nested below the limit are not checked. Zero means unlimited
+	MaxDepth int
+
+	// ShowSource adds the lines surrounding a violation, with a caret under the offending key, to the error
+	ShowSource bool
+
+	// MaxBytes makes linting fail with ErrInputTooLarge for documents larger than the given number of bytes,
+	// guarding against untrusted input exhausting memory. Zero means unlimited
+	MaxBytes int64
+
+	// CheckConst reports properties whose value differs from the const declared for them in the schema
+	CheckConst bool
+
+	// NumericValueEquality compares numbers of the document with numeric consts by their value, so 1.0 and 1e0
+	// match a const of 1. Without it values are compared as written, consts keeping the text they have in the
+	// schema
+	NumericValueEquality bool
+
+	// RequireTrailingNewline reports documents whose last line isn't terminated by a newline
+	RequireTrailingNewline bool
+
+	// EnforceConsistentCase reports keys whose casing style, such as snake_case or camelCase,
+	// differs from the style used by most keys of the document. It doesn't depend on the schema
+	EnforceConsistentCase bool
+
+	// RequireHeadComments reports keys documented with a comment trailing them instead of one on the lines above.
+	// RequireLineComments reports the opposite, so only one of them should be set. They don't depend on the schema
+	RequireHeadComments bool
+	RequireLineComments bool
+
+	// IgnorePlaceholderKeys leaves keys made of a single ${...} placeholder out of every order check and of the
+	// unexpected keys reported by Exact, so templated documents can be linted before substitution
+	IgnorePlaceholderKeys bool
+
+	// CheckMergedKeys also validates the mappings merged with <<, such as anchored defaults, against the schema
+	// of the mapping merging them. Their keys are only compared with each other, never with the keys written
+	// next to the merge key, and Exact doesn't require them to hold every key
+	CheckMergedKeys bool
+
+	// DottedKeys reads keys such as personal.name as paths, validating them as if they were nested under a
+	// personal key placed where its first dotted key is. Keys of a group must also be written next to each other
+	DottedKeys bool
+
+	// KeyOrderRegex requires the keys it matches, in every mapping of the document, to be in ascending order of
+	// the substring captured by group CaptureGroup, zero being the whole match. Captured numbers compare by value,
+	// so with `^release-(\d+)$` and group 1, release-9 comes before release-10. Keys it doesn't match are ignored, and
+	// it doesn't depend on the schema
+	KeyOrderRegex *regexp.Regexp
+	CaptureGroup  int
+
+	// IgnorePrefixes leaves keys starting with any of the prefixes, such as "x-" for vendor extensions, out of
+	// every order check and of the unexpected keys reported by Exact, at every level
+	IgnorePrefixes []string
+
+	// GraceKeys lists keys that may appear anywhere in their object, at every level, such as properties just added to
+	// the schema that existing documents haven't been reordered for. Every other key is still checked against the
+	// others, so a grace key never excuses misplaced keys around it. Under Exact, grace keys the schema lists count as present
+	// Under CanonicalKeys, grace keys match document keys by canonical name
+	GraceKeys []string
+
+	// IgnoreNullValues leaves keys holding null, such as "timeout:" or "timeout: ~", out of every order check and of
+	// the unexpected keys reported by Exact, at every level. Under Exact, null keys the schema lists count as present
+	IgnoreNullValues bool
+
+	// CanonicalKeys matches keys to schema properties, and orders them, by their lowercased names stripped of
+	// "-", "_" and spaces, so user_name in a document is the userName property of the schema. Messages keep names
+	// as written. Schemas holding two properties with the same canonical name at one level are rejected
+	CanonicalKeys bool
+
+	// PrefixOnly requires the keys of every object with properties in the schema to be its first properties,
+	// without skipping any, such as configs filled in step by step. The first skipped property is reported at
+	// the first key following it in the schema
+	PrefixOnly bool
+
+	// UnknownKeysSorted requires the keys missing from the schema, in every object with properties in the schema,
+	// to follow the keys it lists and to be sorted among themselves, compared like the keys of objects marked
+	// "x-order": "alphabetical". Keys the schema lists still follow its order
+	UnknownKeysSorted bool
+
+	// UniformArrayKeys requires the objects of every array the schema lists to hold the same keys as the first of
+	// them, reporting the extra and missing keys of each object that differs
+	UniformArrayKeys bool
+
+	// Exact requires every object with properties in the schema to hold exactly those keys, in schema order.
+	// Unexpected keys, missing keys and keys out of order are reported together in one violation per object
+	Exact bool
+
+	// RequireNonEmptyValues reports keys holding an empty string, such as name: "", or an empty mapping or sequence,
+	// catching half-filled documents. Null values aren't reported. It doesn't depend on the schema
+	RequireNonEmptyValues bool
+
+	// RequireFirstKey reports documents whose root mapping doesn't start with the named key, such as "version".
+	// It doesn't depend on the schema. Empty doesn't require any first key
+	RequireFirstKey string
+
+	// RequiredFirst reports optional properties placed before a required property of the same object,
+	// required properties being those listed in the required array of the schema
+	RequiredFirst bool
+
+	// WarnDeprecated reports keys of properties the schema marks as deprecated with SeverityWarning.
+	// Warnings are returned by LintAll and LintVisit but don't make Lint fail
+	WarnDeprecated bool
+
+	// EnforceDepthRange limits the order checks to the nesting levels from its first to its second element,
+	// inclusive, the root mapping being level 1. Levels outside the range are still traversed to reach deeper ones,
+	// and values are still checked against their const. Zero elements leave that end of the range open
+	EnforceDepthRange [2]int
+
+	// RecordCheckedPaths makes LintReport list in Report.CheckedPaths every key that was checked against a schema
+	// property, proving which keys the schema actually enforced
+	RecordCheckedPaths bool
+
+	// checked is called with the path of every key checked against a schema property, set by LintReport
+	// under RecordCheckedPaths
+	checked func(path string)
+
+	// RequireOptInKey only validates documents whose root mapping holds a key with this name, such as
+	// x-order-enforced, or holding a comment starting with it, such as "# order-schema: strict" for order-schema.
+	// Other documents are skipped without any violation, so files can be opted into enforcement one by one.
+	// Empty validates every document
+	RequireOptInKey string
+
+	// skipped is called when a document is skipped under RequireOptInKey, set by LintAll and LintReport
+	skipped func()
+
+	// Embedded validates string values holding whole documents, such as config files stashed in a ConfigMap,
+	// against their own schema. Violations found inside are reported at their line of the enclosing document for
+	// literal block scalars, such as config.yaml: |, and at the key of the value otherwise, their path continuing
+	// the path of the value
+	Embedded []EmbeddedDocument
+
+	// MaxViolations limits how many violations LintAll and LintReport collect. They keep counting the violations past
+	// the limit, recording how many were left out in LintResult.Truncated and Report.Truncated. Zero means unlimited
+	MaxViolations int
+
+	// Include inlines the documents named by values tagged !include, such as spec: !include spec.yaml, before
+	// validation. It is called with the path as written and returns the YAML or JSON content to inline, which may
+	// include documents in turn. Inlined keys are checked as if written at the include site, where violations
+	// found in them are reported
+	Include func(path string) ([]byte, error)
+
+	// Cache remembers documents found valid, keyed by their content, the schema and the options,
+	// so unchanged documents aren't validated again. It is only consulted by LintWithOptions, LintReader and LintBytes,
+	// and not when Include or Sort.Collator is set
+	Cache Cache
+
+	// SchemaCacheDir is the directory LintURL caches downloaded schemas in, keyed by their URL and ETag.
+	// Empty uses an order/schemas directory inside the user cache directory
+	SchemaCacheDir string
+
+	// NoCache makes LintURL download the schema again on every call, neither reading nor writing SchemaCacheDir
+	NoCache bool
+
+	// BaseDir makes the File of reports relative to the given directory, so annotations resolve
+	// from a subdirectory of the checkout. Empty keeps paths as given
+	BaseDir string
+
+	// SchemaPointer selects the schema to validate against inside the schema file with a JSON pointer,
+	// such as "#/components/schemas/Order" in an OpenAPI spec. Empty uses the whole file
+	SchemaPointer string
+
+	// Sort tunes how keys of objects marked "x-order": "alphabetical" are compared
+	Sort SortOptions
+}
+
+// Lint validates that a YAML or JSON file follows the property order specified in a JSON schema.
+// Neither file is ever modified, Fix being the way to reorder a document
 func Lint(yamlOrJsonPath, jsonSchemaPath string) error {
-	content, err := os.ReadFile(yamlOrJsonPath)
+	return LintWithOptions(yamlOrJsonPath, jsonSchemaPath, LintOptions{})
+}
+
+// LintWithOptions is like Lint but allows tuning validation through LintOptions.
+// Errors about the document are prefixed with its path and violations carry both paths
+func LintWithOptions(yamlOrJsonPath, jsonSchemaPath string, opts LintOptions) error {
+	// Unsupported formats are reported before reading the schema
+	if _, err := parserFor(filepath.Ext(yamlOrJsonPath)); err != nil {
+		return withPath(yamlOrJsonPath, err)
+	}
+
+	// Extract schema properties in their original order
+	schema, err := loadSchemaAt(jsonSchemaPath, opts.SchemaPointer)
 	if err != nil {
 		return err
 	}
+	if err := checkSchemaOptions(schema, opts); err != nil {
+		return withPath(jsonSchemaPath, err)
+	}
 
-	var yamlRoot yaml.Node
-	if strings.HasSuffix(yamlOrJsonPath, ".yaml") || strings.HasSuffix(yamlOrJsonPath, ".yml") {
-		err = yaml.Unmarshal(content, &yamlRoot)
-		if err != nil {
-			return err
+	return lintFile(yamlOrJsonPath, jsonSchemaPath, schema, opts)
+}
+
+// lintFile validates the file at yamlOrJsonPath against schema, read from jsonSchemaPath, returning the first violation
+func lintFile(yamlOrJsonPath, jsonSchemaPath string, schema *SchemaProperty, opts LintOptions) error {
+	ext := filepath.Ext(yamlOrJsonPath)
+	if _, err := parserFor(ext); err != nil {
+		return withPath(yamlOrJsonPath, err)
+	}
+
+	file, err := os.Open(yamlOrJsonPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	content, err := readContent(file, opts)
+	if err != nil {
+		return withPath(yamlOrJsonPath, err)
+	}
+
+	err = lintContent(content, ext, schema, opts)
+	setViolationPaths(err, yamlOrJsonPath, jsonSchemaPath, opts.SchemaPointer)
+
+	return withPath(yamlOrJsonPath, err)
+}
+
+// LintReader is like LintWithOptions but reads the document from r, parsing it with the parser
+// registered for ext, such as ".yaml"
+func LintReader(r io.Reader, ext, jsonSchemaPath string, opts LintOptions) error {
+	if _, err := parserFor(ext); err != nil {
+		return err
+	}
+
+	content, err := readContent(r, opts)
+	if err != nil {
+		return err
+	}
+
+	// Extract schema properties in their original order
+	schema, err := loadSchemaAt(jsonSchemaPath, opts.SchemaPointer)
+	if err != nil {
+		return err
+	}
+	if err := checkSchemaOptions(schema, opts); err != nil {
+		return withPath(jsonSchemaPath, err)
+	}
+
+	err = lintContent(content, ext, schema, opts)
+	setViolationPaths(err, "", jsonSchemaPath, opts.SchemaPointer)
+
+	return err
+}
+
+// LintBytes is like LintWithOptions but validates content, parsing it with the parser registered for ext
+func LintBytes(content []byte, ext, jsonSchemaPath string, opts LintOptions) error {
+	return LintReader(bytes.NewReader(content), ext, jsonSchemaPath, opts)
+}
+
+// LintTopLevel is like Lint but only validates the order of the root mapping against the top-level properties
+// of the schema, skipping nested properties
+func LintTopLevel(yamlOrJsonPath, jsonSchemaPath string) error {
+	return LintWithOptions(yamlOrJsonPath, jsonSchemaPath, LintOptions{MaxDepth: 1})
+}
+
+// LintAgainstProperties is like LintWithOptions but validates against a property tree built in code, or returned by
+// LoadSchema, instead of reading a schema file. Violations leave Schema empty
+func LintAgainstProperties(yamlOrJsonPath string, properties []*SchemaProperty, opts LintOptions) error {
+	schema := &SchemaProperty{Properties: properties}
+	if err := checkSchemaOptions(schema, opts); err != nil {
+		return err
+	}
+
+	return lintFile(yamlOrJsonPath, "", schema, opts)
+}
+
+// lintContent parses content with the parser registered for ext and returns the first violation of schema,
+// consulting LintOptions.Cache first when set
+func lintContent(content []byte, ext string, schema *SchemaProperty, opts LintOptions) error {
+	// Included documents aren't part of the cache key, so documents including others aren't cached
+	if !cacheable(opts) {
+		opts.Cache = nil
+	}
+
+	var key string
+	if opts.Cache != nil {
+		key = cacheKey(content, ext, schema, opts)
+		if valid, ok := opts.Cache.Get(key); ok && valid {
+			return nil
 		}
-	} else if strings.HasSuffix(yamlOrJsonPath, ".json") {
-		// For JSON, we need to parse it in a way that preserves property order
-		jsonReader := strings.NewReader(string(content))
-		jsonNode, err := parseJSONWithOrder(jsonReader)
-		if err != nil {
+	}
+
+	root, err := parseContent(content, ext)
+	if err != nil {
+		return err
+	}
+	if opts.Include != nil {
+		if err := resolveIncludes(root, opts.Include); err != nil {
 			return err
 		}
+	}
 
-		yamlRoot = *jsonNode
-	} else {
-		return errors.New("file must have .yaml, .yml, or .json extension")
+	err = firstViolation(content, root, schema, opts)
+	if opts.Cache != nil {
+		opts.Cache.Set(key, err == nil)
 	}
 
-	// Extract schema properties in their original order
-	schemaProperties, err := extractNestedSchemaOrder(jsonSchemaPath)
+	return err
+}
+
+// withPath prefixes err with the path of the file it concerns.
+// Errors that already name their file, such as those failing to open it, are returned unchanged
+func withPath(path string, err error) error {
+	var pathErr *fs.PathError
+	if err == nil || errors.As(err, &pathErr) {
+		return err
+	}
+
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// setViolationPaths records the document and schema paths on err when it is a violation, along with the pointer
+// selecting the schema inside its file
+func setViolationPaths(err error, file, schema, pointer string) {
+	var violation *Violation
+	if errors.As(err, &violation) {
+		violation.File = file
+		violation.Schema = schema
+		violation.SchemaSource = schemaSource(schema, pointer)
+	}
+}
+
+// schemaSource joins the path of a schema file and the JSON pointer selecting the schema inside it into
+// a reference such as openapi.yaml#/components/schemas/Order
+func schemaSource(schema, pointer string) string {
+	if schema == "" || pointer == "" {
+		return schema
+	}
+	if strings.HasPrefix(pointer, "#") {
+		return schema + pointer
+	}
+
+	return schema + "#" + pointer
+}
+
+// ErrInputTooLarge is returned when a document exceeds LintOptions.MaxBytes, or a schema downloaded by LintURL
+// exceeds the size LintURL accepts
+var ErrInputTooLarge = errors.New("input exceeds the maximum allowed size")
+
+// parseDocument reads the file at yamlOrJsonPath with the parser registered for its extension,
+// returning its content and parsed document node
+func parseDocument(yamlOrJsonPath string, opts LintOptions) ([]byte, *yaml.Node, error) {
+	ext := filepath.Ext(yamlOrJsonPath)
+	if _, err := parserFor(ext); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(yamlOrJsonPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return readDocument(file, ext, opts)
+}
+
+// readDocument reads a document from r with the parser registered for ext,
+// returning its content and parsed document node
+func readDocument(r io.Reader, ext string, opts LintOptions) ([]byte, *yaml.Node, error) {
+	if _, err := parserFor(ext); err != nil {
+		return nil, nil, err
+	}
+
+	content, err := readContent(r, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	yamlRoot, err := parseContent(content, ext)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Include != nil {
+		if err := resolveIncludes(yamlRoot, opts.Include); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return content, yamlRoot, nil
+}
+
+// readContent reads all of r, failing with ErrInputTooLarge past LintOptions.MaxBytes
+func readContent(r io.Reader, opts LintOptions) ([]byte, error) {
+	// Read one byte past the limit to tell a document of exactly MaxBytes from a larger one
+	if opts.MaxBytes > 0 {
+		r = io.LimitReader(r, opts.MaxBytes+1)
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxBytes > 0 && int64(len(content)) > opts.MaxBytes {
+		return nil, ErrInputTooLarge
+	}
+
+	return content, nil
+}
+
+// parseContent parses content with the parser registered for ext into a document node
+func parseContent(content []byte, ext string) (*yaml.Node, error) {
+	parser, err := parserFor(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	yamlRoot, err := parser.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	// Parsers may return the root value directly
+	if yamlRoot.Kind != yaml.DocumentNode {
+		yamlRoot = &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{yamlRoot},
+		}
+	}
+
+	return yamlRoot, nil
+}
+
+// LintAll is like LintWithOptions but reports every violation in the document instead of stopping at the first,
+// collecting them in a LintResult. Within a mapping each key positioned before a key that should follow it
+// is reported once, so a single misplaced key is distinguishable from a reversed block. The error is only set
+// when the document or the schema couldn't be read
+func LintAll(yamlOrJsonPath, jsonSchemaPath string, opts LintOptions) (*LintResult, error) {
+	result := &LintResult{Path: yamlOrJsonPath}
+	opts.skipped = func() {
+		result.Skipped = true
+	}
+	err := lintVisit(yamlOrJsonPath, jsonSchemaPath, opts, func(violation Violation) bool {
+		if opts.MaxViolations > 0 && len(result.Violations) == opts.MaxViolations {
+			result.Truncated++
+		} else {
+			result.Violations = append(result.Violations, violation)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// LintVisit is like LintAll but passes each violation to fn as soon as it is found instead of collecting them,
+// stopping early when fn returns false
+func LintVisit(yamlOrJsonPath, jsonSchemaPath string, fn func(Violation) bool) error {
+	return lintVisit(yamlOrJsonPath, jsonSchemaPath, LintOptions{}, fn)
+}
+
+// lintVisit loads the schema at jsonSchemaPath and streams the violations of the file at yamlOrJsonPath to fn
+func lintVisit(yamlOrJsonPath, jsonSchemaPath string, opts LintOptions, fn func(Violation) bool) error {
+	schema, err := loadSchemaAt(jsonSchemaPath, opts.SchemaPointer)
 	if err != nil {
 		return err
 	}
+	if err := checkSchemaOptions(schema, opts); err != nil {
+		return withPath(jsonSchemaPath, err)
+	}
+
+	return lintFileVisit(yamlOrJsonPath, jsonSchemaPath, schema, opts, fn)
+}
+
+// lintFileAll parses the file at yamlOrJsonPath and returns every violation of schema, read from jsonSchemaPath,
+// it contains
+func lintFileAll(yamlOrJsonPath, jsonSchemaPath string, schema *SchemaProperty, opts LintOptions) ([]Violation, error) {
+	var violations []Violation
+	err := lintFileVisit(yamlOrJsonPath, jsonSchemaPath, schema, opts, func(violation Violation) bool {
+		violations = append(violations, violation)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return violations, nil
+}
+
+// lintFileVisit parses the file at yamlOrJsonPath and passes the violations of schema, read from jsonSchemaPath,
+// to fn until it returns false
+func lintFileVisit(yamlOrJsonPath, jsonSchemaPath string, schema *SchemaProperty, opts LintOptions, fn func(Violation) bool) error {
+	content, root, err := parseDocument(yamlOrJsonPath, opts)
+	if err != nil {
+		return withPath(yamlOrJsonPath, err)
+	}
+
+	lintDocument(content, root, schema, opts, func(violation *Violation) bool {
+		violation.File = yamlOrJsonPath
+		violation.Schema = jsonSchemaPath
+		violation.SchemaSource = schemaSource(jsonSchemaPath, opts.SchemaPointer)
+		return fn(*violation)
+	})
+
+	return nil
+}
+
+// lintDocument validates a parsed document against the schema, reporting violations to visit until it returns false.
+// content is the source the document was parsed from
+func lintDocument(content []byte, yamlRoot *yaml.Node, schema *SchemaProperty, opts LintOptions, visit func(*Violation) bool) {
+	v := &validator{
+		opts:    opts,
+		schema:  schema,
+		content: content,
+		visit: func(violation *Violation) bool {
+			if opts.ShowSource {
+				violation.Snippet = sourceSnippet(content, violation.Line, violation.Column)
+			}
+			return visit(violation)
+		},
+	}
+
+	if opts.RequireOptInKey != "" && !optedIn(yamlRoot, opts.RequireOptInKey) {
+		if opts.skipped != nil {
+			opts.skipped()
+		}
+		return
+	}
 
 	// Validate the YAML document against the schema properties
 	// We start by validating the root level
 	if yamlRoot.Kind == yaml.DocumentNode && len(yamlRoot.Content) > 0 {
 		docNode := yamlRoot.Content[0]
 		if docNode.Kind == yaml.MappingNode {
-			return validateNodeAgainstSchema(docNode, schemaProperties)
+			if opts.RequireFirstKey != "" {
+				v.checkFirstKey(docNode)
+			}
+			v.validateNodeAgainstSchema(docNode, schema, nil, 1)
+			if len(opts.Embedded) > 0 {
+				v.checkEmbeddedDocuments(docNode)
+			}
+		}
+
+		// Documents made of an array of objects follow the items schema, their path naming the index
+		if docNode.Kind == yaml.SequenceNode && schema.Items != nil && schema.Items.hasNestedOrder() {
+			for index, item := range docNode.Content {
+				v.validateNodeAgainstSchema(item, schema.Items, indexPath(nil, index), 1)
+				if v.stopped {
+					break
+				}
+			}
+		}
+
+		if opts.EnforceConsistentCase {
+			v.checkConsistentCase(docNode)
+		}
+		if opts.KeyOrderRegex != nil {
+			v.checkKeyOrderRegex(docNode, nil)
+		}
+		if opts.RequireNonEmptyValues {
+			v.checkNonEmptyValues(docNode, nil)
+		}
+		if opts.RequireHeadComments || opts.RequireLineComments {
+			v.checkCommentPlacement(docNode, nil)
+		}
+	}
+
+	if opts.RequireTrailingNewline && len(content) > 0 && content[len(content)-1] != '\n' {
+		lines := bytes.Split(content, []byte("\n"))
+		last := lines[len(lines)-1]
+		v.reportViolation(&Violation{
+			Line:    len(lines),
+			Column:  utf8.RuneCount(last) + 1,
+			Message: "missing trailing newline",
+		})
+	}
+}
+
+// firstViolation validates a parsed document against the schema, returning the first violation found.
+// Warnings are skipped as they don't make linting fail
+func firstViolation(content []byte, yamlRoot *yaml.Node, schema *SchemaProperty, opts LintOptions) error {
+	var first *Violation
+	lintDocument(content, yamlRoot, schema, opts, func(violation *Violation) bool {
+		if violation.Severity == SeverityWarning {
+			return true
+		}
+		first = violation
+		return false
+	})
+
+	if first == nil {
+		return nil
+	}
+
+	return first
+}
+
+// checkFirstKey reports a root mapping node whose first key isn't LintOptions.RequireFirstKey
+func (v *validator) checkFirstKey(node *yaml.Node) {
+	if len(node.Content) == 0 {
+		v.report(nil, node, "first key should be '"+v.opts.RequireFirstKey+"', found an empty mapping")
+		return
+	}
+
+	if keyNode := node.Content[0]; keyNode.Value != v.opts.RequireFirstKey {
+		v.report(nil, keyNode, "first key should be '"+v.opts.RequireFirstKey+"', found '"+keyNode.Value+"'")
+	}
+}
+
+// validator walks a document against a schema, reporting violations until visit returns false
+type validator struct {
+	opts    LintOptions
+	visit   func(*Violation) bool
+	stopped bool
+
+	// content is the source the document was parsed from, empty when it wasn't parsed from text
+	content []byte
+
+	// schema is the root schema, whose property paths are indexed in propertyPaths the first time a key
+	// unexpected under LintOptions.Exact needs a suggestion
+	schema        *SchemaProperty
+	propertyPaths map[string][]string
+
+	// mergesChecked records the mappings merged with << already validated under LintOptions.CheckMergedKeys
+	mergesChecked map[*yaml.Node]bool
+}
+
+// report passes a violation found in the mapping at path to visit, returning whether validation should continue
+func (v *validator) report(path []string, keyNode *yaml.Node, message string) bool {
+	if v.stopped {
+		return false
+	}
+
+	violation := newViolation(keyNode, message)
+	violation.Path = append([]string(nil), path...)
+
+	return v.reportViolation(violation)
+}
+
+// reportViolation passes violation to visit, returning whether validation should continue
+func (v *validator) reportViolation(violation *Violation) bool {
+	if v.stopped {
+		return false
+	}
+	v.stopped = !v.visit(violation)
+
+	return !v.stopped
+}
+
+// validateNodeAgainstSchema checks if a YAML node's properties are in the correct order according to the schema.
+// path lists the keys leading to node and depth is its nesting level, starting at 1 for the root mapping
+func (v *validator) validateNodeAgainstSchema(node *yaml.Node, schema *SchemaProperty, path []string, depth int) {
+	if node.Kind != yaml.MappingNode {
+		return // Not a mapping, nothing to validate
+	}
+
+	// Violations of the mapping itself are gathered and reported in document order before descending,
+	// so the output doesn't depend on which check found them
+	visit := v.visit
+	var found []*Violation
+	v.visit = func(violation *Violation) bool {
+		found = append(found, violation)
+		return true
+	}
+
+	if v.opts.DottedKeys {
+		node = v.expandDottedKeys(node, path)
+	}
+
+	// Discriminated unions are validated against the branch selected by the discriminator value
+	if schema.Discriminator != "" {
+		branch, keyNode := v.selectBranch(node, schema)
+		if branch != nil {
+			schema = branch
+		} else if keyNode != nil {
+			value := mappingValues(node)[keyNode.Value].Value
+			v.report(path, keyNode, "discriminator '"+keyNode.Value+"' value "+strconv.Quote(value)+
+				" matches no oneOf branch, expected one of "+strings.Join(discriminatorValues(schema), ", "))
+		}
+	}
+
+	propertiesByName := v.indexProperties(schema.Properties)
+	v.checkMapping(node, schema, path, depth, propertiesByName)
+	v.visit = visit
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Line != found[j].Line {
+			return found[i].Line < found[j].Line
+		}
+		return found[i].Column < found[j].Column
+	})
+	for _, violation := range found {
+		if !v.reportViolation(violation) {
+			return
+		}
+	}
+
+	if v.opts.CheckMergedKeys && !v.checkMergeSources(node, schema, path, depth) {
+		return
+	}
+
+	// Nested levels beyond the configured depth aren't validated
+	if v.opts.MaxDepth != 0 && depth >= v.opts.MaxDepth {
+		return
+	}
+
+	// Now recursively validate nested properties
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valueNode := node.Content[i+1]
+
+		// Skip if this property isn't in the schema
+		prop, ok := propertiesByName[v.keyName(keyNode.Value)]
+		if !ok {
+			continue
+		}
+
+		if v.opts.UniformArrayKeys && valueNode.Kind == yaml.SequenceNode &&
+			v.enforcesOrderAt(depth+1) && !v.checkUniformItems(valueNode, append(path, keyNode.Value)) {
+			return
+		}
+
+		if prop.SortItems && valueNode.Kind == yaml.SequenceNode && v.enforcesOrderAt(depth+1) &&
+			!v.checkSortedItems(valueNode, append(path, keyNode.Value)) {
+			return
+		}
+
+		// Mappings inside sequences are validated against the items schema, their path naming the index
+		if valueNode.Kind == yaml.SequenceNode && prop.Items != nil && prop.Items.hasNestedOrder() {
+			for index, item := range valueNode.Content {
+				v.validateNodeAgainstSchema(item, prop.Items, indexPath(append(path, keyNode.Value), index), depth+1)
+				if v.stopped {
+					return
+				}
+			}
+			continue
+		}
+
+		if !prop.hasNestedOrder() || valueNode.Kind != yaml.MappingNode {
+			continue
+		}
+
+		// Validate nested properties
+		v.validateNodeAgainstSchema(valueNode, prop, append(path, keyNode.Value), depth+1)
+		if v.stopped {
+			return
+		}
+	}
+}
+
+// checkUniformItems reports every mapping of the sequence node, found at path, whose keys differ from the keys of
+// its first mapping, returning whether validation should continue. Ignored and merge keys don't count
+func (v *validator) checkUniformItems(node *yaml.Node, path []string) bool {
+	var reference map[string]bool
+	var referenceKeys []string
+	referenceIndex := 0
+
+	for index, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
 		}
+
+		var keys []string
+		present := make(map[string]bool)
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			key := item.Content[i].Value
+			if v.opts.IgnorePlaceholderKeys && isPlaceholderKey(key) || v.hasIgnoredPrefix(key) || isMergeKey(item.Content[i]) {
+				continue
+			}
+			if !present[v.keyName(key)] {
+				present[v.keyName(key)] = true
+				keys = append(keys, key)
+			}
+		}
+
+		if reference == nil {
+			reference, referenceKeys, referenceIndex = present, keys, index
+			continue
+		}
+
+		var unexpected, missing []string
+		for _, key := range keys {
+			if !reference[v.keyName(key)] {
+				unexpected = append(unexpected, "'"+key+"'")
+			}
+		}
+		for _, key := range referenceKeys {
+			if !present[v.keyName(key)] {
+				missing = append(missing, "'"+key+"'")
+			}
+		}
+		if len(unexpected) == 0 && len(missing) == 0 {
+			continue
+		}
+
+		var problems []string
+		if len(unexpected) > 0 {
+			problems = append(problems, "unexpected "+strings.Join(unexpected, ", "))
+		}
+		if len(missing) > 0 {
+			problems = append(problems, "missing "+strings.Join(missing, ", "))
+		}
+
+		referencePath := indexPath(path, referenceIndex)
+		if !v.report(indexPath(path, index), item,
+			"keys differ from '"+referencePath[len(referencePath)-1]+"': "+strings.Join(problems, "; ")) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkMergeSources validates the mappings merged into node with << against the schema of node, found at path
+// and depth, returning whether validation should continue. Each merged mapping is only checked where it is
+// first merged, and Exact doesn't require it to hold every key
+func (v *validator) checkMergeSources(node *yaml.Node, schema *SchemaProperty, path []string, depth int) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !isMergeKey(node.Content[i]) {
+			continue
+		}
+
+		for _, source := range mergeSources(node.Content[i+1]) {
+			if v.mergesChecked[source] {
+				continue
+			}
+			if v.mergesChecked == nil {
+				v.mergesChecked = make(map[*yaml.Node]bool)
+			}
+			v.mergesChecked[source] = true
+
+			opts := v.opts
+			v.opts.Exact = false
+			v.validateNodeAgainstSchema(source, schema, path, depth)
+			v.opts = opts
+			if v.stopped {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// checkMapping checks the keys and values of the mapping node found at path and depth against schema,
+// without descending into nested values
+func (v *validator) checkMapping(node *yaml.Node, schema *SchemaProperty, path []string, depth int,
+	propertiesByName map[string]*SchemaProperty) {
+	schemaProperties := schema.Properties
+
+	// Extract the keys from the YAML mapping in order
+	var keys []string
+	var keyNodes []*yaml.Node
+	var keyPositions = make(map[string]int) // Track position of each key in the actual document
+
+	// Custom parsers may leave a trailing key without a value, which is ignored
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		// Mappings and sequences used as keys have no name to match against the schema
+		if resolveAlias(node.Content[i]).Kind != yaml.ScalarNode {
+			if !v.report(path, node.Content[i], "complex keys are not supported, keys must be scalars") {
+				return
+			}
+			continue
+		}
+
+		key := node.Content[i].Value
+		if v.opts.IgnorePlaceholderKeys && isPlaceholderKey(key) || v.hasIgnoredPrefix(key) || isMergeKey(node.Content[i]) ||
+			v.isExempt(key, node.Content[i+1]) {
+			continue
+		}
+		key = v.keyName(key)
+		if _, ok := propertiesByName[key]; ok && v.opts.checked != nil && v.enforcesOrderAt(depth) {
+			v.opts.checked(strings.Join(appendPath(path, node.Content[i].Value), "."))
+		}
+		keys = append(keys, key)
+		keyNodes = append(keyNodes, node.Content[i])
+		if _, seen := keyPositions[key]; !seen {
+			keyPositions[key] = len(keys) - 1
+		}
+	}
+
+	// Levels outside LintOptions.EnforceDepthRange are traversed without checking their order
+	if v.enforcesOrderAt(depth) {
+		switch {
+		case v.opts.Exact && len(schemaProperties) > 0:
+			// Extra, missing and misplaced keys are consolidated into a single violation
+			if !v.checkExact(node, path, keys, keyNodes, keyPositions, schema, propertiesByName) {
+				return
+			}
+		case len(schema.OrderConstraints) > 0:
+			// Order constraints form a partial order that replaces the total order of the properties
+			for _, constraint := range orderConstraintClosure(schema.OrderConstraints) {
+				posBefore, inDocBefore := keyPositions[v.keyName(constraint[0])]
+				posAfter, inDocAfter := keyPositions[v.keyName(constraint[1])]
+
+				if inDocBefore && inDocAfter && posBefore > posAfter {
+					if !v.report(path, keyNodes[posBefore],
+						"properties out of order: '"+constraint[0]+"' should come before '"+constraint[1]+
+							"' according to the schema order constraints") {
+						return
+					}
+				}
+			}
+		default:
+			// Build a map of property names to their positions in the schema
+			propertyPositions := make(map[string]int)
+			for i, prop := range schemaProperties {
+				propertyPositions[v.keyName(prop.Name)] = i
+			}
+
+			// Check if the properties are in the correct order, reporting each key that precedes one it should follow
+			for i := 0; i < len(keys); i++ {
+				for j := i + 1; j < len(keys); j++ {
+					keyI := keys[i]
+					keyJ := keys[j]
+
+					// Skip keys that aren't in the schema
+					posI, inSchemaI := propertyPositions[keyI]
+					posJ, inSchemaJ := propertyPositions[keyJ]
+
+					// If both keys are in the schema, check their order
+					if inSchemaI && inSchemaJ && posI > posJ {
+						if !v.report(path, keyNodes[i],
+							"properties out of order: '"+keyNodes[i].Value+"' should come after '"+keyNodes[j].Value+
+								"' according to the schema") {
+							return
+						}
+						break
+					}
+				}
+			}
+		}
+
+		// Free-form objects may require their keys to be sorted even though they aren't listed in the schema
+		if schema.Order == OrderAlphabetical {
+			for i := 0; i < len(keys); i++ {
+				for j := i + 1; j < len(keys); j++ {
+					if v.opts.Sort.less(keys[j], keys[i]) {
+						if !v.report(path, keyNodes[i],
+							"properties out of order: '"+keyNodes[i].Value+"' should come after '"+keyNodes[j].Value+
+								"' alphabetically") {
+							return
+						}
+						break
+					}
+				}
+			}
+		}
+
+		if !v.checkSections(path, keyNodes, propertiesByName) {
+			return
+		}
+
+		if v.opts.RequiredFirst && !v.checkRequiredFirst(path, keyNodes, propertiesByName) {
+			return
+		}
+
+		if v.opts.PrefixOnly && !v.checkPrefixOnly(node, path, keys, keyNodes, schema) {
+			return
+		}
+
+		if v.opts.UnknownKeysSorted && len(schemaProperties) > 0 &&
+			!v.checkUnknownKeysSorted(path, keys, keyNodes, propertiesByName) {
+			return
+		}
+	}
+
+	// Properties pinned by the schema must hold their const value, and deprecated ones shouldn't be used
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		prop, ok := propertiesByName[v.keyName(keyNode.Value)]
+		if !ok {
+			continue
+		}
+
+		if v.opts.CheckConst && prop.Const != nil && !v.checkConst(path, keyNode, node.Content[i+1], *prop.Const) {
+			return
+		}
+		if v.opts.WarnDeprecated && prop.Deprecated && !v.warnDeprecated(path, keyNode) {
+			return
+		}
+	}
+}
+
+// selectBranch returns the OneOf branch of schema selected by the value of the discriminator property in the
+// mapping node, along with the key of that property. The branch is nil when no branch matches, the key too when
+// the mapping has no scalar discriminator
+func selectBranch(node *yaml.Node, schema *SchemaProperty) (*SchemaProperty, *yaml.Node) {
+	return (&validator{}).selectBranch(node, schema)
+}
+
+// selectBranch is like the selectBranch function but matches the discriminator key the way v matches keys
+func (v *validator) selectBranch(node *yaml.Node, schema *SchemaProperty) (*SchemaProperty, *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if v.keyName(keyNode.Value) != v.keyName(schema.Discriminator) {
+			continue
+		}
+		if valueNode.Kind != yaml.ScalarNode {
+			return nil, nil
+		}
+
+		for _, branch := range schema.OneOf {
+			for _, value := range branch.DiscriminatorValues {
+				if value == valueNode.Value {
+					return branch, keyNode
+				}
+			}
+		}
+		return nil, keyNode
+	}
+
+	return nil, nil
+}
+
+// discriminatorValues lists the discriminator values selecting any OneOf branch of schema
+func discriminatorValues(schema *SchemaProperty) []string {
+	var values []string
+	for _, branch := range schema.OneOf {
+		values = append(values, branch.DiscriminatorValues...)
+	}
+
+	return values
+}
+
+// checkConst reports a value differing from the const the schema pins it to, returning whether validation should continue
+func (v *validator) checkConst(path []string, keyNode, valueNode *yaml.Node, expected string) bool {
+	if valueNode.Kind == yaml.ScalarNode && valueNode.Value == expected {
+		return true
+	}
+	if v.opts.NumericValueEquality && valueNode.Kind == yaml.ScalarNode && numericEqual(valueNode, expected) {
+		return true
+	}
+
+	actual := strconv.Quote(valueNode.Value)
+	if valueNode.Kind != yaml.ScalarNode {
+		actual = "a non-scalar value"
+	}
+
+	return v.report(path, keyNode,
+		"property '"+keyNode.Value+"' must equal "+strconv.Quote(expected)+", got "+actual)
+}
+
+// numericEqual reports whether the scalar node is a number equal to the number expected holds
+func numericEqual(node *yaml.Node, expected string) bool {
+	if !isNumberTag(node.ShortTag()) {
+		return false
+	}
+
+	actualNumber, errActual := strconv.ParseFloat(node.Value, 64)
+	expectedNumber, errExpected := strconv.ParseFloat(expected, 64)
+
+	return errActual == nil && errExpected == nil && actualNumber == expectedNumber
+}
+
+// warnDeprecated reports the key of a deprecated property as a warning, returning whether validation should continue
+func (v *validator) warnDeprecated(path []string, keyNode *yaml.Node) bool {
+	if v.stopped {
+		return false
+	}
+
+	violation := newViolation(keyNode, "deprecated property '"+keyNode.Value+"'")
+	violation.Path = append([]string(nil), path...)
+	violation.Severity = SeverityWarning
+
+	return v.reportViolation(violation)
+}
+
+// isPlaceholderKey reports whether key consists of a single ${...} placeholder, such as ${REGION} or ${PORT:-80}
+func isPlaceholderKey(key string) bool {
+	return len(key) > 3 && strings.HasPrefix(key, "${") && strings.IndexByte(key, '}') == len(key)-1
+}
+
+// hasIgnoredPrefix reports whether key starts with one of LintOptions.IgnorePrefixes
+func (v *validator) hasIgnoredPrefix(key string) bool {
+	for _, prefix := range v.opts.IgnorePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isExempt reports whether the key holding valueNode is left out of the order checks, being one of
+// LintOptions.GraceKeys or holding null under LintOptions.IgnoreNullValues
+func (v *validator) isExempt(key string, valueNode *yaml.Node) bool {
+	if v.opts.IgnoreNullValues {
+		if value := resolveAlias(valueNode); value.Kind == yaml.ScalarNode && value.ShortTag() == "!!null" {
+			return true
+		}
+	}
+
+	for _, grace := range v.opts.GraceKeys {
+		if v.keyName(key) == v.keyName(grace) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// enforcesOrderAt reports whether the order of keys at the given depth is checked under LintOptions.EnforceDepthRange
+func (v *validator) enforcesOrderAt(depth int) bool {
+	minDepth, maxDepth := v.opts.EnforceDepthRange[0], v.opts.EnforceDepthRange[1]
+	return depth >= minDepth && (maxDepth == 0 || depth <= maxDepth)
+}
+
+// checkExact reports a single violation at the mapping node listing the keys missing from the schema,
+// the schema properties missing from the document and the keys out of order, returning whether validation
+// should continue
+func (v *validator) checkExact(node *yaml.Node, path []string, keys []string, keyNodes []*yaml.Node,
+	keyPositions map[string]int, schema *SchemaProperty, propertiesByName map[string]*SchemaProperty) bool {
+	var unexpected, missing, misplaced []string
+
+	for i, key := range keys {
+		if _, ok := propertiesByName[key]; !ok && keyPositions[key] == i {
+			unexpected = append(unexpected, "'"+keyNodes[i].Value+"'"+v.suggestPaths(keyNodes[i].Value))
+		}
+	}
+	// Exempt keys are left out of keys but still present
+	merged := make(map[string]bool)
+	for key := range mergedKeys(node) {
+		merged[v.keyName(key)] = true
+	}
+	values := make(map[string]*yaml.Node)
+	for key, value := range mappingValues(node) {
+		values[v.keyName(key)] = value
+	}
+	for _, prop := range schema.Properties {
+		name := v.keyName(prop.Name)
+		if value, ok := values[name]; ok && v.isExempt(prop.Name, value) {
+			continue
+		}
+		if _, ok := keyPositions[name]; !ok && !merged[name] {
+			missing = append(missing, "'"+prop.Name+"'")
+		}
+	}
+
+	if len(schema.OrderConstraints) > 0 {
+		for _, constraint := range orderConstraintClosure(schema.OrderConstraints) {
+			posBefore, inDocBefore := keyPositions[v.keyName(constraint[0])]
+			posAfter, inDocAfter := keyPositions[v.keyName(constraint[1])]
+			if inDocBefore && inDocAfter && posBefore > posAfter {
+				misplaced = append(misplaced, "'"+constraint[0]+"' should come before '"+constraint[1]+"'")
+			}
+		}
+	} else {
+		propertyPositions := make(map[string]int)
+		for i, prop := range schema.Properties {
+			propertyPositions[v.keyName(prop.Name)] = i
+		}
+
+		for i := 0; i < len(keys); i++ {
+			posI, inSchemaI := propertyPositions[keys[i]]
+			for j := i + 1; inSchemaI && j < len(keys); j++ {
+				if posJ, inSchemaJ := propertyPositions[keys[j]]; inSchemaJ && posI > posJ {
+					misplaced = append(misplaced, "'"+keyNodes[i].Value+"' should come after '"+keyNodes[j].Value+"'")
+					break
+				}
+			}
+		}
+	}
+
+	if len(unexpected) == 0 && len(missing) == 0 && len(misplaced) == 0 {
+		return true
+	}
+
+	var problems []string
+	if len(unexpected) > 0 {
+		problems = append(problems, "unexpected "+strings.Join(unexpected, ", "))
+	}
+	if len(missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(missing, ", "))
+	}
+	problems = append(problems, misplaced...)
+
+	return v.report(path, node, "properties don't exactly match the schema: "+strings.Join(problems, "; "))
+}
+
+// maxSuggestedPaths caps how many schema paths suggestPaths lists for a key
+const maxSuggestedPaths = 3
+
+// suggestPaths returns a hint naming where else in the schema a property called key exists, such as
+// " (did you mean personal.name?)", or nothing when the schema has no such property
+func (v *validator) suggestPaths(key string) string {
+	if v.propertyPaths == nil {
+		v.propertyPaths = make(map[string][]string)
+		indexPropertyPaths(v.schema, "", v.propertyPaths)
+	}
+
+	paths := v.propertyPaths[key]
+	if len(paths) == 0 {
+		return ""
+	}
+	if len(paths) > maxSuggestedPaths {
+		paths = paths[:maxSuggestedPaths]
+	}
+
+	return " (did you mean " + strings.Join(paths, " or ") + "?)"
+}
+
+// indexPropertyPaths records the dotted path of every property below schema, found at prefix, by property name.
+// Properties of array items are written like rules[].when
+func indexPropertyPaths(schema *SchemaProperty, prefix string, paths map[string][]string) {
+	record := func(prop *SchemaProperty) {
+		path := prefix + prop.Name
+		for _, existing := range paths[prop.Name] {
+			if existing == path {
+				return
+			}
+		}
+		paths[prop.Name] = append(paths[prop.Name], path)
+
+		indexPropertyPaths(prop, path+".", paths)
+		if prop.Items != nil {
+			indexPropertyPaths(prop.Items, path+"[].", paths)
+		}
+	}
+
+	for _, prop := range schema.Properties {
+		record(prop)
+	}
+	for _, branch := range schema.OneOf {
+		for _, prop := range branch.Properties {
+			record(prop)
+		}
+	}
+}
+
+// checkSections reports keys of an x-section appearing after the document already left that section,
+// returning whether validation should continue. Keys missing from the schema don't leave a section
+func (v *validator) checkSections(path []string, keyNodes []*yaml.Node, propertiesByName map[string]*SchemaProperty) bool {
+	current := ""
+	left := make(map[string]string) // Sections already left, mapped to the key that left them
+
+	for _, keyNode := range keyNodes {
+		prop, ok := propertiesByName[v.keyName(keyNode.Value)]
+		if !ok || prop.Section == current {
+			continue
+		}
+
+		if next, ok := left[prop.Section]; ok && prop.Section != "" {
+			if !v.report(path, keyNode,
+				"properties out of section: '"+keyNode.Value+"' belongs to section '"+prop.Section+
+					"', which ended before '"+next+"'") {
+				return false
+			}
+		}
+		if current != "" {
+			if _, ok := left[current]; !ok {
+				left[current] = keyNode.Value
+			}
+		}
+		current = prop.Section
+	}
+
+	return true
+}
+
+// checkRequiredFirst reports optional keys positioned before a required key, returning whether validation should
+// continue. Keys missing from the schema are neither required nor optional
+func (v *validator) checkRequiredFirst(path []string, keyNodes []*yaml.Node, propertiesByName map[string]*SchemaProperty) bool {
+	for i, keyNode := range keyNodes {
+		prop, ok := propertiesByName[v.keyName(keyNode.Value)]
+		if !ok || prop.Required {
+			continue
+		}
+
+		for _, laterNode := range keyNodes[i+1:] {
+			if later, ok := propertiesByName[v.keyName(laterNode.Value)]; ok && later.Required {
+				if !v.report(path, keyNode,
+					"properties out of order: optional '"+keyNode.Value+"' should come after required '"+
+						laterNode.Value+"'") {
+					return false
+				}
+				break
+			}
+		}
+	}
+
+	return true
+}
+
+// checkPrefixOnly reports the first schema property skipped by the keys of the mapping node, found at path, when
+// a later property of the schema is present, returning whether validation should continue. Exempt and merged keys
+// count as present
+func (v *validator) checkPrefixOnly(node *yaml.Node, path []string, keys []string, keyNodes []*yaml.Node,
+	schema *SchemaProperty) bool {
+	present := make(map[string]*yaml.Node, len(keys))
+	for i, key := range keys {
+		if _, ok := present[key]; !ok {
+			present[key] = keyNodes[i]
+		}
+	}
+	merged := make(map[string]bool)
+	for key := range mergedKeys(node) {
+		merged[v.keyName(key)] = true
+	}
+	values := make(map[string]*yaml.Node)
+	for key, value := range mappingValues(node) {
+		values[v.keyName(key)] = value
+	}
+
+	skipped := ""
+	for _, prop := range schema.Properties {
+		name := v.keyName(prop.Name)
+		value, written := values[name]
+		keyNode, checked := present[name]
+
+		switch {
+		case !checked && !merged[name] && !(written && v.isExempt(prop.Name, value)):
+			if skipped == "" {
+				skipped = prop.Name
+			}
+		case skipped != "" && checked:
+			return v.report(path, keyNode,
+				"properties skipped: '"+skipped+"' should be written before '"+keyNode.Value+
+					"', keys should follow the schema order from its first property")
+		}
+	}
+
+	return true
+}
+
+// newViolation creates a violation reported at keyNode
+func newViolation(keyNode *yaml.Node, message string) *Violation {
+	return &Violation{
+		Key:     keyNode.Value,
+		Line:    keyNode.Line,
+		Column:  keyNode.Column,
+		Message: message,
+	}
+}
+
+// hasNestedOrder reports whether the property constrains the order of its own children
+func (p *SchemaProperty) hasNestedOrder() bool {
+	return len(p.Properties) > 0 || len(p.OrderConstraints) > 0 || p.Order != "" || p.Discriminator != ""
+}
+
+// indexPropertiesByName maps the names of properties to the properties, keeping the first of duplicate names
+func indexPropertiesByName(properties []*SchemaProperty) map[string]*SchemaProperty {
+	byName := make(map[string]*SchemaProperty, len(properties))
+	for _, prop := range properties {
+		if _, ok := byName[prop.Name]; !ok {
+			byName[prop.Name] = prop
+		}
+	}
+	return byName
+}
+
+// extractSchemaOrderFromJsonSchemaPath extracts properties names in the order they appear in the original YAML/JSON file
+func extractSchemaOrderFromJsonSchemaPath(jsonSchemaPath string) ([]string, error) {
+	properties, err := extractNestedSchemaOrder(jsonSchemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract top-level property names
+	var propertyNames []string
+	for _, prop := range properties {
+		propertyNames = append(propertyNames, prop.Name)
+	}
+
+	return propertyNames, nil
+}
+
+// extractNestedSchemaOrder extracts properties names in the order they appear in the original YAML/JSON file,
+// including nested properties
+func extractNestedSchemaOrder(jsonSchemaPath string) ([]*SchemaProperty, error) {
+	schema, err := loadSchema(jsonSchemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Properties, nil
+}
+
+// loadSchema reads the JSON schema at jsonSchemaPath into a nameless root property
+func loadSchema(jsonSchemaPath string) (*SchemaProperty, error) {
+	return loadSchemaAt(jsonSchemaPath, "")
+}
+
+// parseJSONSchema parses a JSON schema from an io.Reader and extracts properties in order
+func parseJSONSchema(r io.Reader) ([]*SchemaProperty, error) {
+	schema, err := parseJSONSchemaRoot(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Properties, nil
+}
+
+// parseJSONSchemaRoot parses a JSON schema from an io.Reader into a nameless root property
+// holding the top-level properties and any ordering annotations of the root object
+func parseJSONSchemaRoot(r io.Reader) (*SchemaProperty, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := newJSONDecoder(content)
+	schema, err := parseSchemaRoot(decoder)
+	if err != nil {
+		return nil, decoder.locateError(err)
+	}
+
+	return schema, nil
+}
+
+// parseSchemaRoot parses the schema object read from decoder into a nameless root property
+func parseSchemaRoot(decoder schemaTokens) (*SchemaProperty, error) {
+	// Ensure we're at the start of the JSON object
+	if t, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, decoder.errorf("expected JSON object")
+	}
+
+	root := &SchemaProperty{}
+	found, err := parseSchemaObject(decoder, root, false)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("properties not found")
+	}
+
+	return root, nil
+}
+
+// parsePropertiesObject parses a JSON object that represents schema properties.
+// Some generators emit properties as an array of schemas carrying their name in a name or title field,
+// which is accepted as well
+func parsePropertiesObject(decoder schemaTokens) ([]*SchemaProperty, error) {
+	// Ensure we're at the start of the properties object
+	t, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if t == json.Delim('[') {
+		return parsePropertiesArray(decoder)
+	}
+	if t == nil {
+		return nil, decoder.errorf("schema has null properties, expected an object listing them")
+	}
+	if t != json.Delim('{') {
+		return nil, decoder.errorf("expected properties to be an object")
+	}
+
+	var properties []*SchemaProperty
+	seen := make(map[string]bool)
+
+	// Parse each property
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		// Check if we've reached the end of the properties object
+		if t == json.Delim('}') {
+			break
+		}
+
+		// Get property name
+		propertyName, ok := t.(string)
+		if !ok {
+			return nil, decoder.errorf("expected property name string")
+		}
+		if seen[propertyName] {
+			return nil, decoder.errorf("duplicate property '%s'", propertyName)
+		}
+		seen[propertyName] = true
+
+		// Create the property
+		property := &SchemaProperty{
+			Name: propertyName,
+		}
+
+		// Parse the property object
+		t, err = decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		// Boolean schemas declare the property without constraining its children
+		if _, isBool := t.(bool); isBool {
+			properties = append(properties, property)
+			continue
+		}
+		if t != json.Delim('{') {
+			return nil, decoder.errorf("expected property '%s' to be an object or a boolean", propertyName)
+		}
+
+		if _, err := parseSchemaObject(decoder, property, false); err != nil {
+			return nil, err
+		}
+
+		properties = append(properties, property)
+	}
+
+	return properties, nil
+}
+
+// parsePropertiesArray parses the non-standard array form of properties, whose opening bracket
+// has already been consumed, where each entry is a schema naming its property in a name or title field
+func parsePropertiesArray(decoder schemaTokens) ([]*SchemaProperty, error) {
+	var properties []*SchemaProperty
+	seen := make(map[string]bool)
+
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		// Check if we've reached the end of the properties array
+		if t == json.Delim(']') {
+			break
+		}
+
+		if t != json.Delim('{') {
+			return nil, decoder.errorf("expected properties array entry to be an object")
+		}
+		line, column := decoder.tokenPosition()
+
+		property := &SchemaProperty{}
+		if _, err := parseSchemaObject(decoder, property, true); err != nil {
+			return nil, err
+		}
+		if property.Name == "" {
+			return nil, fmt.Errorf("expected properties array entry to have a name or title at line %d, column %d", line, column)
+		}
+		if seen[property.Name] {
+			return nil, fmt.Errorf("duplicate property '%s' at line %d, column %d", property.Name, line, column)
+		}
+		seen[property.Name] = true
+
+		properties = append(properties, property)
+	}
+
+	return properties, nil
+}
+
+// parseSchemaObject reads the fields of a schema object whose opening brace has already been consumed,
+// storing nested properties and ordering annotations on property. It reports whether any of them were found.
+// When named is set the property takes its name from a name field, falling back to title
+func parseSchemaObject(decoder schemaTokens, property *SchemaProperty, named bool) (bool, error) {
+	found := false
+	title := ""
+	var required []string
+	var discriminatorMapping [][2]string
+	var allOf []*SchemaProperty
+
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			return false, err
+		}
+
+		// Check if we've reached the end of this object
+		if t == json.Delim('}') {
+			if named && property.Name == "" {
+				property.Name = title
+			}
+			if len(allOf) > 0 {
+				property.Properties = inheritProperties(allOf, property.Properties)
+			}
+			markRequired(property.Properties, required)
+			assignDiscriminatorValues(property, discriminatorMapping)
+			return found, nil
+		}
+
+		key, _ := t.(string)
+		if named && (key == "name" || key == "title") {
+			t, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+
+			value, ok := nameToken(decoder, t)
+			if !ok {
+				return false, decoder.errorf("expected %s of properties array entry to be a string", key)
+			}
+			if key == "name" {
+				property.Name = value
+			} else {
+				title = value
+			}
+			continue
+		}
+
+		switch key {
+		case "properties":
+			// Parse nested properties
+			nestedProperties, err := parsePropertiesObject(decoder)
+			if err != nil {
+				return false, err
+			}
+			property.Properties = nestedProperties
+			found = true
+		case "x-order-constraints":
+			constraints, err := parseOrderConstraints(decoder)
+			if err != nil {
+				return false, err
+			}
+			property.OrderConstraints = constraints
+			found = true
+		case "const":
+			t, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+
+			// Only scalar constants can be compared with document values
+			if value, ok := jsonScalarValue(t); ok {
+				property.Const = &value
+			} else if err := skipJSONToken(decoder, t); err != nil {
+				return false, err
+			}
+		case "required":
+			required, err = parseRequired(decoder)
+			if err != nil {
+				return false, err
+			}
+		case "items":
+			t, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+
+			// Only a single schema applying to every element is supported, tuple and boolean forms are skipped
+			if t != json.Delim('{') {
+				if err := skipJSONToken(decoder, t); err != nil {
+					return false, err
+				}
+				continue
+			}
+
+			items := &SchemaProperty{}
+			itemsFound, err := parseSchemaObject(decoder, items, false)
+			if err != nil {
+				return false, err
+			}
+			property.Items = items
+			found = found || itemsFound
+		case "oneOf":
+			branches, branchesFound, err := parseSubschemas(decoder)
+			if err != nil {
+				return false, err
+			}
+			property.OneOf = branches
+			found = found || branchesFound
+		case "allOf":
+			subschemas, subschemasFound, err := parseSubschemas(decoder)
+			if err != nil {
+				return false, err
+			}
+			allOf = subschemas
+			found = found || subschemasFound
+		case "discriminator":
+			property.Discriminator, discriminatorMapping, err = parseDiscriminator(decoder)
+			if err != nil {
+				return false, err
+			}
+			found = found || property.Discriminator != ""
+		case "$ref":
+			// Resolved references keep their $ref, naming the schema for discriminators
+			t, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+			if ref, ok := t.(string); ok {
+				property.ref = ref
+			} else if err := skipJSONToken(decoder, t); err != nil {
+				return false, err
+			}
+		case "deprecated":
+			t, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+
+			// Other values are left for schema validators to reject
+			if deprecated, ok := t.(bool); ok {
+				property.Deprecated = deprecated
+			} else if err := skipJSONToken(decoder, t); err != nil {
+				return false, err
+			}
+		case "x-section":
+			t, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+
+			section, ok := t.(string)
+			if !ok {
+				return false, decoder.errorf("expected x-section to be a string")
+			}
+			property.Section = section
+		case "x-order":
+			t, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+
+			order, ok := t.(string)
+			if !ok || order != OrderAlphabetical {
+				return false, decoder.errorf("unsupported x-order value %v, expected %q", t, OrderAlphabetical)
+			}
+			property.Order = order
+			found = true
+		case "x-sort-items":
+			t, err := decoder.Token()
+			if err != nil {
+				return false, err
+			}
+
+			sortItems, ok := t.(bool)
+			if !ok {
+				return false, decoder.errorf("expected x-sort-items to be a boolean")
+			}
+			property.SortItems = sortItems
+			found = found || sortItems
+		default:
+			// Skip the value of this field
+			if err := skipJSONValue(decoder); err != nil {
+				return false, err
+			}
+		}
+	}
+}
+
+// parseSubschemas parses the schemas of a oneOf or allOf array, reporting whether any of them carries ordering
+// information. Entries that aren't objects, such as boolean schemas, are skipped
+func parseSubschemas(decoder schemaTokens) ([]*SchemaProperty, bool, error) {
+	t, err := decoder.Token()
+	if err != nil {
+		return nil, false, err
+	}
+	if t != json.Delim('[') {
+		return nil, false, skipJSONToken(decoder, t)
+	}
+
+	var branches []*SchemaProperty
+	found := false
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		if t == json.Delim(']') {
+			return branches, found, nil
+		}
+		if t != json.Delim('{') {
+			if err := skipJSONToken(decoder, t); err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+
+		branch := &SchemaProperty{}
+		branchFound, err := parseSchemaObject(decoder, branch, false)
+		if err != nil {
+			return nil, false, err
+		}
+		branches = append(branches, branch)
+		found = found || branchFound
+	}
+}
+
+// inheritProperties returns the properties of a schema composed with allOf: those of each subschema in allOf order,
+// followed by the schema's own. A property defined more than once keeps the position of its first definition,
+// later definitions only adding nested properties and filling settings it leaves unset, as MergeSchemaProperties does
+func inheritProperties(allOf []*SchemaProperty, own []*SchemaProperty) []*SchemaProperty {
+	var properties []*SchemaProperty
+	for _, subschema := range allOf {
+		properties = MergeSchemaProperties(properties, subschema.Properties)
 	}
 
-	return nil
+	return MergeSchemaProperties(properties, own)
 }
 
-// validateNodeAgainstSchema checks if a YAML node's properties are in the correct order according to the schema
-func validateNodeAgainstSchema(node *yaml.Node, schemaProperties []*SchemaProperty) error {
-	if node.Kind != yaml.MappingNode {
-		return nil // Not a mapping, nothing to validate
+// parseDiscriminator parses a discriminator object, returning its propertyName and its mapping
+// of discriminator values to schema references, in the order they are listed
+func parseDiscriminator(decoder schemaTokens) (string, [][2]string, error) {
+	t, err := decoder.Token()
+	if err != nil {
+		return "", nil, err
 	}
-
-	// Build a map of property names to their positions in the schema
-	propertyPositions := make(map[string]int)
-	for i, prop := range schemaProperties {
-		propertyPositions[prop.Name] = i
+	if t != json.Delim('{') {
+		return "", nil, decoder.errorf("expected discriminator to be an object")
 	}
 
-	// Extract the keys from the YAML mapping in order
-	var keys []string
-	var keyPositions = make(map[string]int) // Track position of each key in the actual document
+	var propertyName string
+	var mapping [][2]string
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			return "", nil, err
+		}
+		if t == json.Delim('}') {
+			return propertyName, mapping, nil
+		}
 
-	for i := 0; i < len(node.Content); i += 2 {
-		key := node.Content[i].Value
-		keys = append(keys, key)
-		keyPositions[key] = i / 2
-	}
+		switch t {
+		case "propertyName":
+			t, err := decoder.Token()
+			if err != nil {
+				return "", nil, err
+			}
 
-	// Check if the properties are in the correct order
-	for i := 0; i < len(keys); i++ {
-		for j := i + 1; j < len(keys); j++ {
-			keyI := keys[i]
-			keyJ := keys[j]
+			name, ok := nameToken(decoder, t)
+			if !ok {
+				return "", nil, decoder.errorf("expected discriminator propertyName to be a string")
+			}
+			propertyName = name
+		case "mapping":
+			t, err := decoder.Token()
+			if err != nil {
+				return "", nil, err
+			}
+			if t != json.Delim('{') {
+				return "", nil, decoder.errorf("expected discriminator mapping to be an object")
+			}
 
-			// Skip keys that aren't in the schema
-			posI, inSchemaI := propertyPositions[keyI]
-			posJ, inSchemaJ := propertyPositions[keyJ]
+			for {
+				t, err := decoder.Token()
+				if err != nil {
+					return "", nil, err
+				}
+				if t == json.Delim('}') {
+					break
+				}
 
-			// If both keys are in the schema, check their order
-			if inSchemaI && inSchemaJ && posI > posJ {
-				return errors.New(
-					"properties out of order: '" + keyI + "' should come after '" + keyJ +
-						"' according to the schema")
+				value, _ := t.(string)
+				t, err = decoder.Token()
+				if err != nil {
+					return "", nil, err
+				}
+				ref, ok := t.(string)
+				if !ok {
+					return "", nil, decoder.errorf("expected discriminator mapping of %q to be a string", value)
+				}
+				mapping = append(mapping, [2]string{value, ref})
+			}
+		default:
+			if err := skipJSONValue(decoder); err != nil {
+				return "", nil, err
 			}
 		}
 	}
+}
 
-	// Now recursively validate nested properties
-	for i := 0; i < len(node.Content); i += 2 {
-		keyNode := node.Content[i]
-		valueNode := node.Content[i+1]
+// assignDiscriminatorValues records on each OneOf branch of property the discriminator values selecting it.
+// A branch is selected by the values mapping to the schema it refers to, by the const of its discriminator
+// property and, when no mapping names it, by the name of that schema as in OpenAPI
+func assignDiscriminatorValues(property *SchemaProperty, mapping [][2]string) {
+	if property.Discriminator == "" {
+		return
+	}
 
-		// Skip if this property isn't in the schema
-		prop, ok := findPropertyByName(schemaProperties, keyNode.Value)
-		if !ok || len(prop.Properties) == 0 || valueNode.Kind != yaml.MappingNode {
-			continue
+	for _, branch := range property.OneOf {
+		name := schemaName(branch.ref)
+		mapped := false
+		for _, entry := range mapping {
+			if name != "" && schemaName(entry[1]) == name {
+				branch.DiscriminatorValues = append(branch.DiscriminatorValues, entry[0])
+				mapped = true
+			}
 		}
 
-		// Validate nested properties
-		err := validateNodeAgainstSchema(valueNode, prop.Properties)
-		if err != nil {
-			return errors.New("in property '" + keyNode.Value + "': " + err.Error())
+		if prop, ok := indexPropertiesByName(branch.Properties)[property.Discriminator]; ok && prop.Const != nil {
+			branch.DiscriminatorValues = append(branch.DiscriminatorValues, *prop.Const)
+		}
+		if !mapped && name != "" {
+			branch.DiscriminatorValues = append(branch.DiscriminatorValues, name)
 		}
 	}
-
-	return nil
 }
 
-// findPropertyByName finds a property in a slice of properties by its name
-func findPropertyByName(properties []*SchemaProperty, name string) (*SchemaProperty, bool) {
-	for _, prop := range properties {
-		if prop.Name == name {
-			return prop, true
-		}
+// schemaName returns the name of the schema a reference points to, the last segment of its fragment
+// or the file name without extension. Bare names, as allowed in discriminator mappings, are returned unchanged
+func schemaName(ref string) string {
+	location, fragment, hasFragment := strings.Cut(ref, "#")
+	if hasFragment && fragment != "" {
+		return fragment[strings.LastIndex(fragment, "/")+1:]
 	}
-	return nil, false
+
+	location = path.Base(location)
+	return strings.TrimSuffix(location, path.Ext(location))
 }
 
-// extractSchemaOrderFromJsonSchemaPath extracts properties names in the order they appear in the original YAML/JSON file
-func extractSchemaOrderFromJsonSchemaPath(jsonSchemaPath string) ([]string, error) {
-	properties, err := extractNestedSchemaOrder(jsonSchemaPath)
-	if err != nil {
-		return nil, err
+// nameToken returns the property name t spells. Scalars of YAML schemas are read as written, so an entry such as
+// true, null or 1.0 names the key of the same text rather than a boolean, null or number
+func nameToken(decoder schemaTokens, t json.Token) (string, bool) {
+	if name, ok := t.(string); ok {
+		return name, true
 	}
-
-	// Extract top-level property names
-	var propertyNames []string
-	for _, prop := range properties {
-		propertyNames = append(propertyNames, prop.Name)
+	if tokenizer, ok := decoder.(*nodeTokenizer); ok {
+		return tokenizer.tokenText()
 	}
 
-	return propertyNames, nil
+	return "", false
 }
 
-// extractNestedSchemaOrder extracts properties names in the order they appear in the original YAML/JSON file,
-// including nested properties
-func extractNestedSchemaOrder(jsonSchemaPath string) ([]*SchemaProperty, error) {
-	file, err := os.Open(jsonSchemaPath)
+// parseRequired parses a required array of property names. Other values, such as the boolean required
+// of draft 3 schemas, are skipped
+func parseRequired(decoder schemaTokens) ([]string, error) {
+	t, err := decoder.Token()
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	return parseJSONSchema(file)
-}
-
-// parseJSONSchema parses a JSON schema from an io.Reader and extracts properties in order
-func parseJSONSchema(r io.Reader) ([]*SchemaProperty, error) {
-	decoder := json.NewDecoder(r)
-
-	// Ensure we're at the start of the JSON object
-	if t, err := decoder.Token(); err != nil {
-		return nil, err
-	} else if t != json.Delim('{') {
-		return nil, errors.New("expected JSON object")
+	if t != json.Delim('[') {
+		return nil, skipJSONToken(decoder, t)
 	}
 
-	// Look for the "properties" field
+	var required []string
 	for {
 		t, err := decoder.Token()
 		if err != nil {
 			return nil, err
 		}
 
-		// Check if we've reached the end of the object
-		if t == json.Delim('}') {
-			return nil, errors.New("properties not found")
+		// Check if we've reached the end of the required array
+		if t == json.Delim(']') {
+			return required, nil
 		}
 
-		// Check if we found the properties key
-		if key, ok := t.(string); ok && key == "properties" {
-			// Parse the properties object
-			return parsePropertiesObject(decoder)
+		name, ok := nameToken(decoder, t)
+		if !ok {
+			return nil, decoder.errorf("expected required entry to be a string")
 		}
+		required = append(required, name)
+	}
+}
 
-		// Skip the value of this field since it's not "properties"
-		if err := skipJSONValue(decoder); err != nil {
-			return nil, err
+// markRequired sets Required on the properties named in required
+func markRequired(properties []*SchemaProperty, required []string) {
+	for _, name := range required {
+		for _, prop := range properties {
+			if prop.Name == name {
+				prop.Required = true
+			}
 		}
 	}
 }
 
-// parsePropertiesObject parses a JSON object that represents schema properties
-func parsePropertiesObject(decoder *json.Decoder) ([]*SchemaProperty, error) {
-	// Ensure we're at the start of the properties object
+// parseOrderConstraints parses an x-order-constraints array of [before, after] pairs
+// and makes sure the pairs describe an acyclic ordering
+func parseOrderConstraints(decoder schemaTokens) ([][2]string, error) {
 	if t, err := decoder.Token(); err != nil {
 		return nil, err
-	} else if t != json.Delim('{') {
-		return nil, errors.New("expected properties object")
+	} else if t != json.Delim('[') {
+		return nil, decoder.errorf("expected x-order-constraints array")
 	}
 
-	var properties []*SchemaProperty
-
-	// Parse each property
+	var constraints [][2]string
 	for {
 		t, err := decoder.Token()
 		if err != nil {
 			return nil, err
 		}
 
-		// Check if we've reached the end of the properties object
-		if t == json.Delim('}') {
+		// Check if we've reached the end of the constraints array
+		if t == json.Delim(']') {
 			break
 		}
 
-		// Get property name
-		propertyName, ok := t.(string)
-		if !ok {
-			return nil, errors.New("expected property name string")
-		}
-
-		// Create the property
-		property := &SchemaProperty{
-			Name: propertyName,
-		}
-
-		// Parse the property object
-		if t, err := decoder.Token(); err != nil {
-			return nil, err
-		} else if t != json.Delim('{') {
-			return nil, errors.New("expected property object")
+		if t != json.Delim('[') {
+			return nil, decoder.errorf("expected x-order-constraints entry to be a [before, after] array")
 		}
+		line, column := decoder.tokenPosition()
 
-		// Look for nested "properties" in this property
+		// Read the pair of property names
+		var pair []string
 		for {
 			t, err := decoder.Token()
 			if err != nil {
 				return nil, err
 			}
-
-			// Check if we've reached the end of this property
-			if t == json.Delim('}') {
+			if t == json.Delim(']') {
 				break
 			}
 
-			// Check if this is a nested "properties" field
-			if key, ok := t.(string); ok && key == "properties" {
-				// Parse nested properties
-				nestedProperties, err := parsePropertiesObject(decoder)
-				if err != nil {
-					return nil, err
-				}
-				property.Properties = nestedProperties
-			} else {
-				// Skip the value of this field
-				if err := skipJSONValue(decoder); err != nil {
-					return nil, err
-				}
+			name, ok := nameToken(decoder, t)
+			if !ok {
+				return nil, decoder.errorf("expected x-order-constraints entry to contain property name strings")
 			}
+			pair = append(pair, name)
 		}
 
-		properties = append(properties, property)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("expected x-order-constraints entry to be a [before, after] array at line %d, column %d", line, column)
+		}
+		constraints = append(constraints, [2]string{pair[0], pair[1]})
 	}
 
-	return properties, nil
+	if err := checkOrderConstraints(constraints); err != nil {
+		return nil, err
+	}
+
+	return constraints, nil
+}
+
+// checkOrderConstraints topologically sorts the graph described by constraints and errors if it contains a cycle
+func checkOrderConstraints(constraints [][2]string) error {
+	// Build the adjacency list and count incoming edges of every node
+	edges := make(map[string][]string)
+	inDegree := make(map[string]int)
+	var nodes []string
+	for _, constraint := range constraints {
+		for _, name := range constraint {
+			if _, ok := inDegree[name]; !ok {
+				inDegree[name] = 0
+				nodes = append(nodes, name)
+			}
+		}
+		edges[constraint[0]] = append(edges[constraint[0]], constraint[1])
+		inDegree[constraint[1]]++
+	}
+
+	// Repeatedly remove nodes without incoming edges
+	var queue []string
+	for _, name := range nodes {
+		if inDegree[name] == 0 {
+			queue = append(queue, name)
+		}
+	}
+
+	sorted := 0
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		sorted++
+
+		for _, next := range edges[name] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	// Any node left over is part of a cycle
+	if sorted < len(nodes) {
+		for _, name := range nodes {
+			if inDegree[name] > 0 {
+				return errors.New("x-order-constraints contain a cycle involving '" + name + "'")
+			}
+		}
+	}
+
+	return nil
+}
+
+// orderConstraintClosure returns every [before, after] pair implied by constraints, so a before b and b before c
+// also yield a before c, keys being ordered even when the keys linking them are missing from a document.
+// Pairs are listed by their before key, in the order keys first appear in constraints
+func orderConstraintClosure(constraints [][2]string) [][2]string {
+	edges := make(map[string][]string)
+	var nodes []string
+	seen := make(map[string]bool)
+	for _, constraint := range constraints {
+		for _, name := range constraint {
+			if !seen[name] {
+				seen[name] = true
+				nodes = append(nodes, name)
+			}
+		}
+		edges[constraint[0]] = append(edges[constraint[0]], constraint[1])
+	}
+
+	var closure [][2]string
+	for _, name := range nodes {
+		reached := map[string]bool{name: true}
+		queue := append([]string(nil), edges[name]...)
+		for len(queue) > 0 {
+			next := queue[0]
+			queue = queue[1:]
+			if reached[next] {
+				continue
+			}
+			reached[next] = true
+			closure = append(closure, [2]string{name, next})
+			queue = append(queue, edges[next]...)
+		}
+	}
+
+	return closure
 }
 
 // skipJSONValue skips over a JSON value (object, array, or primitive)
-func skipJSONValue(decoder *json.Decoder) error {
+func skipJSONValue(decoder schemaTokens) error {
 	t, err := decoder.Token()
 	if err != nil {
 		return err
 	}
 
+	return skipJSONToken(decoder, t)
+}
+
+// skipJSONToken skips the rest of a JSON value whose first token t has already been consumed
+func skipJSONToken(decoder schemaTokens, t json.Token) error {
 	switch t {
 	case json.Delim('{'):
 		// Skip object
@@ -314,266 +2204,3 @@ func skipJSONValue(decoder *json.Decoder) error {
 
 	return nil
 }
-
-// parseJSONWithOrder parses JSON content while preserving property order
-func parseJSONWithOrder(r io.Reader) (*yaml.Node, error) {
-	// Create a document node as the root
-	doc := &yaml.Node{
-		Kind: yaml.DocumentNode,
-	}
-
-	// Parse the JSON content
-	obj, err := parseJSONObject(json.NewDecoder(r))
-	if err != nil {
-		return nil, err
-	}
-
-	// Add the parsed object as content of the document
-	doc.Content = append(doc.Content, obj)
-
-	return doc, nil
-}
-
-// parseJSONObject parses a JSON object into a YAML mapping node
-func parseJSONObject(decoder *json.Decoder) (*yaml.Node, error) {
-	// Ensure we're at the start of an object
-	t, err := decoder.Token()
-	if err != nil {
-		return nil, err
-	}
-	if t != json.Delim('{') {
-		return nil, errors.New("expected JSON object")
-	}
-
-	// Create a mapping node for the object
-	obj := &yaml.Node{
-		Kind: yaml.MappingNode,
-	}
-
-	// Parse key-value pairs
-	for {
-		// Read the next token, which should be a key or closing brace
-		t, err := decoder.Token()
-		if err != nil {
-			return nil, err
-		}
-
-		// Check if we've reached the end of the object
-		if t == json.Delim('}') {
-			break
-		}
-
-		// Get the key name
-		key, ok := t.(string)
-		if !ok {
-			return nil, errors.New("expected string key in JSON object")
-		}
-
-		// Create a scalar node for the key
-		keyNode := &yaml.Node{
-			Kind:  yaml.ScalarNode,
-			Value: key,
-		}
-
-		// Parse the value
-		valueNode, err := parseJSONValue(decoder)
-		if err != nil {
-			return nil, err
-		}
-
-		// Add the key-value pair to the mapping
-		obj.Content = append(obj.Content, keyNode, valueNode)
-	}
-
-	return obj, nil
-}
-
-// parseJSONValue parses a JSON value into a YAML node
-func parseJSONValue(decoder *json.Decoder) (*yaml.Node, error) {
-	t, err := decoder.Token()
-	if err != nil {
-		return nil, err
-	}
-
-	switch v := t.(type) {
-	case string:
-		return &yaml.Node{
-			Kind:  yaml.ScalarNode,
-			Value: v,
-		}, nil
-	case float64:
-		return &yaml.Node{
-			Kind:  yaml.ScalarNode,
-			Value: fmt.Sprintf("%g", v),
-		}, nil
-	case bool:
-		return &yaml.Node{
-			Kind:  yaml.ScalarNode,
-			Value: fmt.Sprintf("%t", v),
-		}, nil
-	case nil:
-		return &yaml.Node{
-			Kind:  yaml.ScalarNode,
-			Value: "null",
-		}, nil
-	case json.Delim:
-		if v == '{' {
-			// For object, we need special handling as we've already consumed the opening brace
-			objNode := &yaml.Node{
-				Kind: yaml.MappingNode,
-			}
-
-			// Parse key-value pairs
-			for {
-				// Read key or closing brace
-				keyToken, err := decoder.Token()
-				if err != nil {
-					return nil, err
-				}
-
-				// Check if we've reached the end of the object
-				if keyToken == json.Delim('}') {
-					break
-				}
-
-				// Get the key name
-				key, ok := keyToken.(string)
-				if !ok {
-					return nil, errors.New("expected string key in JSON object")
-				}
-
-				// Create a scalar node for the key
-				keyNode := &yaml.Node{
-					Kind:  yaml.ScalarNode,
-					Value: key,
-				}
-
-				// Parse the value
-				valNode, err := parseJSONValue(decoder)
-				if err != nil {
-					return nil, err
-				}
-
-				// Add the key-value pair to the mapping
-				objNode.Content = append(objNode.Content, keyNode, valNode)
-			}
-
-			return objNode, nil
-		} else if v == '[' {
-			return parseJSONArray(decoder)
-		}
-	}
-
-	return nil, errors.New("unexpected JSON value")
-}
-
-// parseJSONArray parses a JSON array into a YAML sequence node
-func parseJSONArray(decoder *json.Decoder) (*yaml.Node, error) {
-	// Create a sequence node for the array
-	arr := &yaml.Node{
-		Kind: yaml.SequenceNode,
-	}
-
-	// Parse array elements
-	for {
-		// Peek at the next token
-		t, err := decoder.Token()
-		if err != nil {
-			return nil, err
-		}
-
-		// Check if we've reached the end of the array
-		if t == json.Delim(']') {
-			break
-		}
-
-		// Handle the token based on its type
-		var valueNode *yaml.Node
-
-		switch v := t.(type) {
-		case string:
-			valueNode = &yaml.Node{
-				Kind:  yaml.ScalarNode,
-				Value: v,
-			}
-		case float64:
-			valueNode = &yaml.Node{
-				Kind:  yaml.ScalarNode,
-				Value: fmt.Sprintf("%g", v),
-			}
-		case bool:
-			valueNode = &yaml.Node{
-				Kind:  yaml.ScalarNode,
-				Value: fmt.Sprintf("%t", v),
-			}
-		case nil:
-			valueNode = &yaml.Node{
-				Kind:  yaml.ScalarNode,
-				Value: "null",
-			}
-		case json.Delim:
-			if v == '{' {
-				// For nested objects in arrays, we need special handling
-				// because we've already consumed the opening brace
-				objNode := &yaml.Node{
-					Kind: yaml.MappingNode,
-				}
-
-				// Parse key-value pairs
-				for {
-					// Read key or closing brace
-					keyToken, err := decoder.Token()
-					if err != nil {
-						return nil, err
-					}
-
-					// Check if we've reached the end of the object
-					if keyToken == json.Delim('}') {
-						break
-					}
-
-					// Get the key name
-					key, ok := keyToken.(string)
-					if !ok {
-						return nil, errors.New("expected string key in JSON object")
-					}
-
-					// Create a scalar node for the key
-					keyNode := &yaml.Node{
-						Kind:  yaml.ScalarNode,
-						Value: key,
-					}
-
-					// Parse the value
-					valNode, err := parseJSONValue(decoder)
-					if err != nil {
-						return nil, err
-					}
-
-					// Add the key-value pair to the mapping
-					objNode.Content = append(objNode.Content, keyNode, valNode)
-				}
-
-				valueNode = objNode
-			} else if v == '[' {
-				// For nested arrays, recursively parse
-				nestedArr, err := parseJSONArray(decoder)
-				if err != nil {
-					return nil, err
-				}
-				valueNode = nestedArr
-			} else {
-				return nil, errors.New("unexpected JSON delimiter")
-			}
-		default:
-			return nil, errors.New("unexpected JSON value type in array")
-		}
-
-		// Add the value to the array
-		if valueNode != nil {
-			arr.Content = append(arr.Content, valueNode)
-		}
-	}
-
-	return arr, nil
-}
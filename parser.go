@@ -0,0 +1,82 @@
+package order
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parser parses a document format into a YAML node tree whose mappings keep the key order of the source.
+// Parse may return either a document node or the root value node
+type Parser interface {
+	Parse(r io.Reader) (*yaml.Node, error)
+}
+
+// yamlParser parses YAML documents
+type yamlParser struct{}
+
+// Parse parses the first YAML document of r
+func (yamlParser) Parse(r io.Reader) (*yaml.Node, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	err = yaml.Unmarshal(content, &node)
+	if err != nil {
+		return nil, err
+	}
+
+	return &node, nil
+}
+
+// jsonParser parses JSON documents while preserving property order
+type jsonParser struct{}
+
+// Parse parses the JSON document of r
+func (jsonParser) Parse(r io.Reader) (*yaml.Node, error) {
+	return parseJSONWithOrder(r)
+}
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]Parser{
+		".yaml": yamlParser{},
+		".yml":  yamlParser{},
+		".json": jsonParser{},
+	}
+)
+
+// RegisterParser makes Lint parse files with the extension ext, such as ".toml", using p.
+// Registering an extension that already has a parser replaces it
+func RegisterParser(ext string, p Parser) {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	parsers[ext] = p
+}
+
+// parserFor returns the parser registered for the extension ext, with or without its leading dot
+func parserFor(ext string) (Parser, error) {
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+
+	p, ok := parsers[ext]
+	if !ok {
+		return nil, fmt.Errorf("no parser registered for %q files, expected .yaml, .yml, .json or an extension added with RegisterParser", ext)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,150 @@
+package order
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyValueParser parses flat "key=value" lines, standing in for a custom format
+type keyValueParser struct{}
+
+func (keyValueParser) Parse(r io.Reader) (*yaml.Node, error) {
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key, Line: line, Column: 1},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value, Line: line, Column: len(key) + 2},
+		)
+	}
+
+	return mapping, scanner.Err()
+}
+
+// danglingKeyParser returns a mapping whose last key has no value node
+type danglingKeyParser struct{}
+
+func (danglingKeyParser) Parse(io.Reader) (*yaml.Node, error) {
+	return &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "first", Line: 1, Column: 1},
+		{Kind: yaml.MappingNode, Line: 1, Column: 8},
+		{Kind: yaml.ScalarNode, Value: "second", Line: 2, Column: 1},
+	}}, nil
+}
+
+// arrayTablesParser parses "key = value" lines and [[name]] headers, standing in for a TOML parser.
+// Like TOML arrays of tables, every [[name]] header appends a table to the sequence held by name
+type arrayTablesParser struct{}
+
+func (arrayTablesParser) Parse(r io.Reader) (*yaml.Node, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sequences := make(map[string]*yaml.Node)
+	table := root
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if name, ok := strings.CutPrefix(text, "[["); ok {
+			name = strings.TrimSuffix(name, "]]")
+			sequence, ok := sequences[name]
+			if !ok {
+				sequence = &yaml.Node{Kind: yaml.SequenceNode, Line: line, Column: 1}
+				sequences[name] = sequence
+				root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name, Line: line, Column: 3}, sequence)
+			}
+
+			table = &yaml.Node{Kind: yaml.MappingNode, Line: line + 1, Column: 1}
+			sequence.Content = append(sequence.Content, table)
+			continue
+		}
+
+		key, value, _ := strings.Cut(text, " = ")
+		table.Content = append(table.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key, Line: line, Column: 1},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value, Line: line, Column: len(key) + 4},
+		)
+	}
+
+	return root, scanner.Err()
+}
+
+func TestRegisterParser(t *testing.T) {
+	tempDir := t.TempDir()
+
+	RegisterParser("kv", keyValueParser{})
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"first": {}, "second": {}}}`)
+
+	t.Run("Custom format in order", func(t *testing.T) {
+		validPath := writeTestFile(t, tempDir, "valid.kv", "first=1\nsecond=2\n")
+
+		err := Lint(validPath, schemaPath)
+		if err != nil {
+			t.Errorf("Lint() returned an error for a registered format in order: %v", err)
+		}
+	})
+
+	t.Run("Custom format out of order", func(t *testing.T) {
+		invalidPath := writeTestFile(t, tempDir, "invalid.kv", "second=2\nfirst=1\n")
+
+		err := Lint(invalidPath, schemaPath)
+		if err == nil {
+			t.Errorf("Lint() did not return an error for a registered format out of order")
+		} else if !strings.Contains(err.Error(), "'second' should come after 'first'") {
+			t.Errorf("Lint() returned unexpected error for a registered format: %v", err)
+		}
+	})
+
+	t.Run("Key without a value", func(t *testing.T) {
+		RegisterParser("dangling", danglingKeyParser{})
+		danglingPath := writeTestFile(t, tempDir, "config.dangling", "")
+
+		err := Lint(danglingPath, schemaPath)
+		if err != nil {
+			t.Errorf("Lint() returned an error for a mapping with a trailing key: %v", err)
+		}
+	})
+
+	t.Run("Arrays of tables", func(t *testing.T) {
+		RegisterParser("tables", arrayTablesParser{})
+		serversSchemaPath := writeTestFile(t, tempDir, "servers.json", `{
+  "properties": {
+    "title": {},
+    "servers": {"type": "array", "items": {"properties": {"host": {}, "port": {}}}}
+  }
+}`)
+		tablesPath := writeTestFile(t, tempDir, "config.tables", `title = "fleet"
+[[servers]]
+host = "alpha"
+port = 8080
+[[servers]]
+port = 9090
+host = "beta"
+`)
+
+		violations, err := lintAll(tablesPath, serversSchemaPath, LintOptions{})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+
+		expected := "in property 'servers[1]': properties out of order: 'port' should come after 'host' according to the schema"
+		if len(violations) != 1 || violations[0].Error() != expected || violations[0].Line != 6 {
+			t.Errorf("LintAll() returned unexpected violations for arrays of tables: %+v", violations)
+		}
+	})
+
+	t.Run("Unregistered extension", func(t *testing.T) {
+		unknownPath := writeTestFile(t, tempDir, "config.ini", "first=1\n")
+
+		err := Lint(unknownPath, schemaPath)
+		if err == nil || !strings.Contains(err.Error(), ".ini") {
+			t.Errorf("Lint() did not return an error naming an unregistered extension: %v", err)
+		}
+	})
+}
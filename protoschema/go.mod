@@ -0,0 +1,15 @@
+module github.com/roscrl/order/protoschema
+
+go 1.23.3
+
+require (
+	github.com/roscrl/order v0.0.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	golang.org/x/text v0.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/roscrl/order => ../
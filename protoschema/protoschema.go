@@ -0,0 +1,77 @@
+// Package protoschema builds order property trees from Protobuf message descriptors, so documents can be
+// validated against the field order of a .proto file
+package protoschema
+
+import (
+	"sort"
+
+	"github.com/roscrl/order"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Options configures how SchemaFromProtoDescriptorWithOptions turns fields into properties
+type Options struct {
+	// ByNumber orders fields by field number instead of the order they are declared in
+	ByNumber bool
+
+	// JSONNames names properties after the JSON names of fields, such as displayName, instead of their
+	// proto names, such as display_name
+	JSONNames bool
+}
+
+// SchemaFromProtoDescriptor builds the property tree of md with its fields in declaration order, named after
+// their proto names. Message fields nest the properties of their message and repeated message fields
+// list them as array items
+func SchemaFromProtoDescriptor(md protoreflect.MessageDescriptor) []*order.SchemaProperty {
+	return SchemaFromProtoDescriptorWithOptions(md, Options{})
+}
+
+// SchemaFromProtoDescriptorWithOptions is like SchemaFromProtoDescriptor but allows tuning the tree through Options.
+// Map fields and well-known types such as google.protobuf.Timestamp, which aren't written as objects of their
+// fields, have no nested properties. A message nested in itself isn't expanded past the point where it recurses
+func SchemaFromProtoDescriptorWithOptions(md protoreflect.MessageDescriptor, opts Options) []*order.SchemaProperty {
+	return messageProperties(md, opts, make(map[protoreflect.FullName]bool))
+}
+
+// messageProperties returns the properties of the fields of md. expanding holds the messages whose properties
+// are being built, which are left empty when reached again
+func messageProperties(md protoreflect.MessageDescriptor, opts Options, expanding map[protoreflect.FullName]bool) []*order.SchemaProperty {
+	if expanding[md.FullName()] || md.ParentFile() != nil && md.ParentFile().Package() == "google.protobuf" {
+		return nil
+	}
+	expanding[md.FullName()] = true
+	defer delete(expanding, md.FullName())
+
+	fields := make([]protoreflect.FieldDescriptor, md.Fields().Len())
+	for i := range fields {
+		fields[i] = md.Fields().Get(i)
+	}
+	if opts.ByNumber {
+		sort.SliceStable(fields, func(i, j int) bool {
+			return fields[i].Number() < fields[j].Number()
+		})
+	}
+
+	properties := make([]*order.SchemaProperty, 0, len(fields))
+	for _, field := range fields {
+		prop := &order.SchemaProperty{Name: string(field.Name())}
+		if opts.JSONNames {
+			prop.Name = field.JSONName()
+		}
+
+		if field.Message() != nil && !field.IsMap() {
+			children := messageProperties(field.Message(), opts, expanding)
+			switch {
+			case len(children) == 0:
+			case field.IsList():
+				prop.Items = &order.SchemaProperty{Properties: children}
+			default:
+				prop.Properties = children
+			}
+		}
+
+		properties = append(properties, prop)
+	}
+
+	return properties
+}
@@ -0,0 +1,175 @@
+package protoschema
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roscrl/order"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+)
+
+// serviceDescriptor builds the descriptor of
+//
+//	message Service {
+//	  string display_name = 2;
+//	  Spec spec = 1;
+//	  repeated Port ports = 3;
+//	  map<string, Port> named_ports = 4;
+//	  google.protobuf.Duration timeout = 5;
+//	}
+//	message Spec { int32 replicas = 1; string image = 2; Spec fallback = 3; }
+//	message Port { string name = 1; int32 port = 2; }
+func serviceDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	field := func(name string, number int32, label descriptorpb.FieldDescriptorProto_Label,
+		kind descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  label.Enum(),
+			Type:   kind.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	message := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+	int32Type := descriptorpb.FieldDescriptorProto_TYPE_INT32
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("config/service.proto"),
+		Package:    proto.String("config"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/duration.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Service"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("display_name", 2, optional, str, ""),
+					field("spec", 1, optional, message, ".config.Spec"),
+					field("ports", 3, repeated, message, ".config.Port"),
+					field("named_ports", 4, repeated, message, ".config.Service.NamedPortsEntry"),
+					field("timeout", 5, optional, message, ".google.protobuf.Duration"),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("NamedPortsEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("key", 1, optional, str, ""),
+						field("value", 2, optional, message, ".config.Port"),
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			},
+			{
+				Name: proto.String("Spec"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("replicas", 1, optional, int32Type, ""),
+					field("image", 2, optional, str, ""),
+					field("fallback", 3, optional, message, ".config.Spec"),
+				},
+			},
+			{
+				Name: proto.String("Port"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, optional, str, ""),
+					field("port", 2, optional, int32Type, ""),
+				},
+			},
+		},
+	}
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("protodesc.NewFile() returned an error: %v", err)
+	}
+
+	return fd.Messages().ByName("Service")
+}
+
+// propertyNames renders properties as name(children) for comparison, array items written as name[children]
+func propertyNames(properties []*order.SchemaProperty) string {
+	var names []string
+	for _, prop := range properties {
+		name := prop.Name
+		if len(prop.Properties) > 0 {
+			name += "(" + propertyNames(prop.Properties) + ")"
+		}
+		if prop.Items != nil {
+			name += "[" + propertyNames(prop.Items.Properties) + "]"
+		}
+		names = append(names, name)
+	}
+
+	return strings.Join(names, " ")
+}
+
+func TestSchemaFromProtoDescriptor(t *testing.T) {
+	md := serviceDescriptor(t)
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "Declaration order",
+			expected: "display_name spec(replicas image fallback) ports[name port] named_ports timeout",
+		},
+		{
+			name:     "Field number order",
+			opts:     Options{ByNumber: true},
+			expected: "spec(replicas image fallback) display_name ports[name port] named_ports timeout",
+		},
+		{
+			name:     "JSON names",
+			opts:     Options{JSONNames: true},
+			expected: "displayName spec(replicas image fallback) ports[name port] namedPorts timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := propertyNames(SchemaFromProtoDescriptorWithOptions(md, tt.opts))
+			if got != tt.expected {
+				t.Errorf("SchemaFromProtoDescriptorWithOptions() returned %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Linting against the descriptor", func(t *testing.T) {
+		tempDir := t.TempDir()
+
+		validPath := filepath.Join(tempDir, "valid.yaml")
+		invalidPath := filepath.Join(tempDir, "invalid.yaml")
+		if err := os.WriteFile(validPath, []byte("display_name: web\nspec:\n  replicas: 2\nports:\n  - name: http\n    port: 80\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.WriteFile(invalidPath, []byte("display_name: web\nports:\n  - port: 80\n    name: http\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		properties := SchemaFromProtoDescriptor(md)
+		if err := order.LintAgainstProperties(validPath, properties, order.LintOptions{}); err != nil {
+			t.Errorf("LintAgainstProperties() returned an error for a document in field order: %v", err)
+		}
+
+		err := order.LintAgainstProperties(invalidPath, properties, order.LintOptions{})
+		var violation *order.Violation
+		if !errors.As(err, &violation) || violation.Key != "port" || violation.Line != 3 {
+			t.Errorf("LintAgainstProperties() returned unexpected error for a document out of field order: %v", err)
+		}
+	})
+}
@@ -0,0 +1,466 @@
+package order
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaTokens yields the JSON tokens of a schema along with their position in the source,
+// letting the schema parser read raw JSON as well as already parsed node trees
+type schemaTokens interface {
+	Token() (json.Token, error)
+	tokenPosition() (int, int)
+	errorf(format string, args ...any) error
+}
+
+// nodeToken is a JSON token produced from a node, located where the node starts.
+// Tokens of scalars keep the text of the scalar as written
+type nodeToken struct {
+	token        json.Token
+	line, column int
+	text         string
+	scalar       bool
+}
+
+// nodeTokenizer yields the JSON tokens equivalent to a YAML or JSON node tree
+type nodeTokenizer struct {
+	tokens []nodeToken
+	next   int
+}
+
+// newNodeTokenizer flattens node into the tokens a JSON decoder would return for it
+func newNodeTokenizer(node *yaml.Node) *nodeTokenizer {
+	t := &nodeTokenizer{}
+	t.appendNode(node)
+
+	return t
+}
+
+// appendNode appends the tokens of node and its children
+func (t *nodeTokenizer) appendNode(node *yaml.Node) {
+	node = resolveAlias(node)
+	add := func(token json.Token) {
+		t.tokens = append(t.tokens, nodeToken{token: token, line: node.Line, column: node.Column})
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			t.appendNode(node.Content[0])
+		}
+	case yaml.MappingNode:
+		add(json.Delim('{'))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			t.tokens = append(t.tokens, nodeToken{token: keyNode.Value, line: keyNode.Line, column: keyNode.Column})
+			t.appendNode(node.Content[i+1])
+		}
+		add(json.Delim('}'))
+	case yaml.SequenceNode:
+		add(json.Delim('['))
+		for _, item := range node.Content {
+			t.appendNode(item)
+		}
+		add(json.Delim(']'))
+	default:
+		t.tokens = append(t.tokens, nodeToken{token: scalarToken(node), line: node.Line, column: node.Column, text: node.Value, scalar: true})
+	}
+}
+
+// scalarToken converts a scalar node to the token a JSON decoder using UseNumber returns for the same value,
+// numbers becoming a json.Number of their text. Values with other tags are kept as strings
+func scalarToken(node *yaml.Node) json.Token {
+	if tag := node.ShortTag(); tag == "!!int" || tag == "!!float" {
+		return json.Number(node.Value)
+	}
+
+	var value any
+	if node.Decode(&value) != nil {
+		return node.Value
+	}
+
+	switch v := value.(type) {
+	case bool, nil:
+		return v
+	}
+
+	return node.Value
+}
+
+// Token returns the next token, or io.EOF after the last one
+func (t *nodeTokenizer) Token() (json.Token, error) {
+	if t.next >= len(t.tokens) {
+		return nil, io.EOF
+	}
+	t.next++
+
+	return t.tokens[t.next-1].token, nil
+}
+
+// tokenPosition returns the line and column of the node the most recently returned token came from
+func (t *nodeTokenizer) tokenPosition() (int, int) {
+	if t.next == 0 {
+		return 1, 1
+	}
+
+	token := t.tokens[t.next-1]
+	return token.line, token.column
+}
+
+// tokenText returns the text of the scalar the most recently returned token came from, and whether it was a scalar
+func (t *nodeTokenizer) tokenText() (string, bool) {
+	if t.next == 0 {
+		return "", false
+	}
+
+	token := t.tokens[t.next-1]
+	return token.text, token.scalar
+}
+
+// errorf returns an error located at the node of the most recently returned token
+func (t *nodeTokenizer) errorf(format string, args ...any) error {
+	line, column := t.tokenPosition()
+	return fmt.Errorf(format+" at line %d, column %d", append(args, line, column)...)
+}
+
+// loadSchemaAt reads the schema found at pointer inside the JSON or YAML file at schemaPath, resolving the $ref
+// references it contains. An empty pointer selects the whole file
+func loadSchemaAt(schemaPath, pointer string) (*SchemaProperty, error) {
+	root, err := loadSchemaFile(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := parseSchemaDocumentAt(root, schemaPath, pointer)
+	if err != nil {
+		return nil, withPath(schemaPath, err)
+	}
+
+	return schema, nil
+}
+
+// loadSchemaFile reads the schema document at path, YAML when its extension is .yaml or .yml and JSON otherwise,
+// returning its root node
+func loadSchemaFile(path string) (*yaml.Node, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseSchemaFile(content, filepath.Ext(path))
+	if err != nil {
+		return nil, withPath(path, err)
+	}
+
+	return root, nil
+}
+
+// parseSchemaFile parses content, YAML when ext is .yaml or .yml and JSON otherwise, returning its root node
+func parseSchemaFile(content []byte, ext string) (*yaml.Node, error) {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		var document yaml.Node
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return nil, err
+		}
+		if len(document.Content) == 0 {
+			return nil, fmt.Errorf("expected JSON object")
+		}
+		return document.Content[0], nil
+	default:
+		document, err := parseJSONWithOrder(strings.NewReader(string(content)))
+		if err != nil {
+			return nil, err
+		}
+		return document.Content[0], nil
+	}
+}
+
+// parseSchemaDocumentAt parses the schema found at pointer below root, the root node of the schema file at
+// schemaPath against which relative references are resolved
+func parseSchemaDocumentAt(root *yaml.Node, schemaPath, pointer string) (*SchemaProperty, error) {
+	return parseSchemaDocumentWith(root, schemaPath, pointer, loadSchemaFile)
+}
+
+// parseSchemaDocumentWith is like parseSchemaDocumentAt but loads the other schema files references point to,
+// by absolute path, with loadFile
+func parseSchemaDocumentWith(root *yaml.Node, schemaPath, pointer string,
+	loadFile func(path string) (*yaml.Node, error)) (*SchemaProperty, error) {
+	target, err := resolvePointer(root, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newRefResolver(schemaPath, root)
+	if err != nil {
+		return nil, err
+	}
+	r.loadFile = loadFile
+	target, err = r.resolve(target, r.rootFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSchemaRoot(newNodeTokenizer(target))
+}
+
+// resolvePointer returns the node the JSON pointer selects below root. The pointer may be written
+// as a URI fragment starting with #, whose percent-encoded characters are decoded first
+func resolvePointer(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	path := pointer
+	if strings.HasPrefix(path, "#") {
+		unescaped, err := url.PathUnescape(path[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON pointer %q: %w", pointer, err)
+		}
+		path = unescaped
+	}
+	if path == "" {
+		return root, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q, expected it to start with /", pointer)
+	}
+
+	node := root
+	for _, token := range strings.Split(path[1:], "/") {
+		// ~1 must be replaced before ~0, so "~01" stays the literal "~1"
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		node = resolveAlias(node)
+		next, ok := pointerChild(node, token)
+		if !ok {
+			return nil, fmt.Errorf("JSON pointer %q not found, no %q at line %d, column %d", pointer, token, node.Line, node.Column)
+		}
+		node = next
+	}
+
+	return resolveAlias(node), nil
+}
+
+// pointerChild returns the value of the key token in a mapping, or the element at index token in a sequence
+func pointerChild(node *yaml.Node, token string) (*yaml.Node, bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == token {
+				return node.Content[i+1], true
+			}
+		}
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(token)
+		if err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index], true
+		}
+	}
+
+	return nil, false
+}
+
+// refResolver replaces $ref objects of a schema with the node they refer to, loading the schema files
+// that references point to relative to the file holding them
+type refResolver struct {
+	// rootFile is the absolute path of the schema file being parsed
+	rootFile string
+	// files caches the root node of every schema file loaded, by absolute path
+	files map[string]*yaml.Node
+	// resolved caches references already replaced by their fully resolved target, by absolute file and fragment
+	resolved map[string]*yaml.Node
+	// resolving holds the references being resolved, to detect recursive schemas
+	resolving map[string]bool
+	// loadFile loads the schema files references point to, by absolute path
+	loadFile func(path string) (*yaml.Node, error)
+}
+
+// newRefResolver returns a resolver for references found in root, the root node of the schema file at schemaPath
+func newRefResolver(schemaPath string, root *yaml.Node) (*refResolver, error) {
+	rootFile, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &refResolver{
+		rootFile:  rootFile,
+		files:     map[string]*yaml.Node{rootFile: root},
+		resolved:  make(map[string]*yaml.Node),
+		resolving: make(map[string]bool),
+		loadFile:  loadSchemaFile,
+	}, nil
+}
+
+// resolve returns node, found in the schema file at the absolute path file, with every $ref object below it
+// replaced by its resolved target, leaving node unchanged. Siblings of $ref are ignored, and a recursive reference
+// becomes an empty schema, so ordering isn't checked below the point where a schema refers to itself
+func (r *refResolver) resolve(node *yaml.Node, file string) (*yaml.Node, error) {
+	node = resolveAlias(node)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if refNode := refValue(node); refNode != nil {
+			target, err := r.resolveRef(refNode, file)
+			if err != nil {
+				return nil, err
+			}
+			return withRef(target, node), nil
+		}
+	case yaml.SequenceNode:
+	default:
+		return node, nil
+	}
+
+	// Copy the node whose children are replaced
+	resolved := *node
+	resolved.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		// Mapping keys are never schemas
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			resolved.Content[i] = child
+			continue
+		}
+
+		resolvedChild, err := r.resolve(child, file)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Content[i] = resolvedChild
+	}
+
+	return &resolved, nil
+}
+
+// resolveRef returns the resolved target of the reference held by refNode, found in the schema file at the
+// absolute path file. A reference to another file is resolved relative to the directory of file.
+// References that only lead to each other without reaching a schema are reported as circular
+func (r *refResolver) resolveRef(refNode *yaml.Node, file string) (*yaml.Node, error) {
+	var chain []string
+	var target *yaml.Node
+	for refNode != nil {
+		targetFile, key, node, err := r.lookupRef(refNode, file)
+		if err != nil {
+			return nil, err
+		}
+
+		if resolved, ok := r.resolved[key]; ok {
+			return resolved, nil
+		}
+		if r.resolving[key] {
+			return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: refNode.Line, Column: refNode.Column}, nil
+		}
+		for _, previous := range chain {
+			if previous == key {
+				return nil, fmt.Errorf("circular $ref %q at %s, the references never reach a schema",
+					refNode.Value, r.refPosition(refNode, file))
+			}
+		}
+
+		chain = append(chain, key)
+		file, target = targetFile, node
+		refNode = refValue(target)
+	}
+
+	for _, key := range chain {
+		r.resolving[key] = true
+	}
+	resolved, err := r.resolve(target, file)
+	for _, key := range chain {
+		delete(r.resolving, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range chain {
+		r.resolved[key] = resolved
+	}
+
+	return resolved, nil
+}
+
+// lookupRef returns the node the reference held by refNode, found in the schema file at the absolute path file,
+// points to along with the absolute path of the file holding that node and a key identifying the target
+func (r *refResolver) lookupRef(refNode *yaml.Node, file string) (string, string, *yaml.Node, error) {
+	ref := refNode.Value
+	location, fragment, _ := strings.Cut(ref, "#")
+	if strings.Contains(location, "://") {
+		return "", "", nil, fmt.Errorf("unsupported $ref %q at %s, only references to local files are supported",
+			ref, r.refPosition(refNode, file))
+	}
+
+	targetFile := file
+	if location != "" {
+		targetFile = filepath.Join(filepath.Dir(file), filepath.FromSlash(location))
+	}
+
+	root, ok := r.files[targetFile]
+	if !ok {
+		var err error
+		root, err = r.loadFile(targetFile)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("$ref %q at %s: %w", ref, r.refPosition(refNode, file), err)
+		}
+		r.files[targetFile] = root
+	}
+
+	target, err := resolvePointer(root, "#"+fragment)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("$ref at %s: %w", r.refPosition(refNode, file), err)
+	}
+
+	return targetFile, targetFile + "#" + fragment, target, nil
+}
+
+// refPosition describes where refNode, found in the schema file at the absolute path file, is.
+// Files other than the one being parsed are named relative to its directory
+func (r *refResolver) refPosition(refNode *yaml.Node, file string) string {
+	position := fmt.Sprintf("line %d, column %d", refNode.Line, refNode.Column)
+	if file == r.rootFile {
+		return position
+	}
+
+	if relative, err := filepath.Rel(filepath.Dir(r.rootFile), file); err == nil {
+		file = filepath.ToSlash(relative)
+	}
+
+	return position + " of " + file
+}
+
+// withRef returns a copy of the resolved target of the $ref object refObject keeping its $ref entry, which
+// records the schema name discriminators can refer to. Targets that aren't mappings are returned unchanged
+func withRef(target, refObject *yaml.Node) *yaml.Node {
+	if target.Kind != yaml.MappingNode {
+		return target
+	}
+
+	for i := 0; i+1 < len(refObject.Content); i += 2 {
+		if refObject.Content[i].Value == "$ref" {
+			resolved := *target
+			resolved.Content = append(append([]*yaml.Node(nil), target.Content...), refObject.Content[i], refObject.Content[i+1])
+			return &resolved
+		}
+	}
+
+	return target
+}
+
+// refValue returns the value of the $ref key of a mapping node, or nil when it has none
+func refValue(node *yaml.Node) *yaml.Node {
+	node = resolveAlias(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "$ref" && node.Content[i+1].Kind == yaml.ScalarNode {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
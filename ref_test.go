@@ -0,0 +1,285 @@
+package order
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLintSchemaPointer(t *testing.T) {
+	tempDir := t.TempDir()
+
+	specPath := writeTestFile(t, tempDir, "openapi.json", `{
+  "openapi": "3.0.3",
+  "paths": {
+    "/orders": {
+      "post": {
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {"$ref": "#/components/schemas/Order"}
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Order": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "customer": {"$ref": "#/components/schemas/Customer"},
+          "lines": {"type": "array", "items": {"$ref": "#/components/schemas/Line"}}
+        }
+      },
+      "Customer": {
+        "properties": {"name": {}, "email": {}}
+      },
+      "Line": {
+        "properties": {"sku": {}, "quantity": {}, "parent": {"$ref": "#/components/schemas/Line"}}
+      }
+    }
+  }
+}`)
+	pointer := "#/paths/~1orders/post/requestBody/content/application~1json/schema"
+
+	t.Run("Example in order", func(t *testing.T) {
+		validPath := writeTestFile(t, tempDir, "valid.json", `{
+  "id": "1",
+  "customer": {"name": "Ada", "email": "ada@example.com"},
+  "lines": [{"sku": "a", "quantity": 1, "parent": {"sku": "b", "quantity": 2}}]
+}`)
+
+		err := LintWithOptions(validPath, specPath, LintOptions{SchemaPointer: pointer})
+		if err != nil {
+			t.Errorf("LintWithOptions() returned an error for an example in order: %v", err)
+		}
+	})
+
+	t.Run("Referenced schemas are enforced", func(t *testing.T) {
+		invalidPath := writeTestFile(t, tempDir, "invalid.json", `{
+  "id": "1",
+  "customer": {"email": "ada@example.com", "name": "Ada"},
+  "lines": [{"quantity": 1, "sku": "a"}]
+}`)
+
+		violations, err := lintAll(invalidPath, specPath, LintOptions{SchemaPointer: pointer})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+		if len(violations) != 2 {
+			t.Fatalf("LintAll() returned %d violations, expected 2: %v", len(violations), violations)
+		}
+		if violations[0].Path[0] != "customer" || violations[1].Path[0] != "lines[0]" {
+			t.Errorf("LintAll() returned unexpected violations: %v", violations)
+		}
+	})
+
+	t.Run("Percent-encoded pointer", func(t *testing.T) {
+		validPath := writeTestFile(t, tempDir, "customer.yaml", "name: Ada\nemail: ada@example.com\n")
+
+		err := LintWithOptions(validPath, specPath, LintOptions{SchemaPointer: "#/components/schemas/Customer"})
+		if err != nil {
+			t.Errorf("LintWithOptions() returned an error for a schema selected by pointer: %v", err)
+		}
+
+		encodedPointer := "#/paths/~1orders/post/requestBody/content/application~1json/schema"
+		encodedPointer = strings.Replace(encodedPointer, "application", "%61pplication", 1)
+		err = LintWithOptions(writeTestFile(t, tempDir, "order.yaml", "id: 1\n"), specPath, LintOptions{SchemaPointer: encodedPointer})
+		if err != nil {
+			t.Errorf("LintWithOptions() returned an error for a percent-encoded pointer: %v", err)
+		}
+	})
+
+	t.Run("YAML spec", func(t *testing.T) {
+		yamlSpecPath := writeTestFile(t, tempDir, "openapi.yaml", `openapi: 3.0.3
+components:
+  schemas:
+    Customer:
+      properties:
+        name: {}
+        email: {}
+`)
+		invalidPath := writeTestFile(t, tempDir, "customer_invalid.yaml", "email: ada@example.com\nname: Ada\n")
+
+		err := LintWithOptions(invalidPath, yamlSpecPath, LintOptions{SchemaPointer: "#/components/schemas/Customer"})
+		if err == nil || !strings.Contains(err.Error(), "'email' should come after 'name'") {
+			t.Errorf("LintWithOptions() did not return the expected error against a YAML spec: %v", err)
+		}
+
+		var violation *Violation
+		source := yamlSpecPath + "#/components/schemas/Customer"
+		if !errors.As(err, &violation) || violation.Schema != yamlSpecPath || violation.SchemaSource != source {
+			t.Errorf("LintWithOptions() returned a violation not naming its schema source %s: %+v", source, violation)
+		}
+
+		report, err := LintReport(invalidPath, yamlSpecPath, LintOptions{SchemaPointer: "/components/schemas/Customer"})
+		if err != nil {
+			t.Fatalf("LintReport() returned an error: %v", err)
+		}
+		if report.SchemaSource != source || len(report.Violations) != 1 || report.Violations[0].SchemaSource != source {
+			t.Errorf("LintReport() returned a report not naming its schema source %s: %+v", source, report)
+		}
+	})
+
+	t.Run("Unresolvable pointers and references", func(t *testing.T) {
+		validPath := writeTestFile(t, tempDir, "doc.yaml", "id: 1\n")
+		brokenSpecPath := writeTestFile(t, tempDir, "broken.json", `{
+  "properties": {
+    "dangling": {"$ref": "#/definitions/Missing"},
+    "remote": {"$ref": "https://example.com/schema.json"}
+  }
+}`)
+
+		tests := []struct {
+			name     string
+			spec     string
+			pointer  string
+			expected string
+		}{
+			{"Missing pointer target", specPath, "#/components/schemas/Nope", `JSON pointer "#/components/schemas/Nope" not found, no "Nope" at line 17, column 16`},
+			{"Relative pointer", specPath, "components", "expected it to start with /"},
+			{"Dangling reference", brokenSpecPath, "#/properties/dangling", "$ref at line 3, column 26"},
+			{"Remote reference", brokenSpecPath, "#/properties/remote", `unsupported $ref "https://example.com/schema.json" at line 4, column 24`},
+		}
+
+		for _, tt := range tests {
+			err := LintWithOptions(validPath, tt.spec, LintOptions{SchemaPointer: tt.pointer})
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("%s: LintWithOptions() returned %v, expected an error containing %q", tt.name, err, tt.expected)
+			}
+		}
+	})
+}
+
+func TestResolvePointer(t *testing.T) {
+	var root yaml.Node
+	err := yaml.Unmarshal([]byte(`
+"a/b": slash
+"m~n": tilde
+"~1": escaped
+list: [zero, one]
+`), &root)
+	if err != nil {
+		t.Fatalf("Failed to parse test document: %v", err)
+	}
+
+	tests := map[string]string{
+		"/a~1b":   "slash",
+		"/m~0n":   "tilde",
+		"/~01":    "escaped",
+		"#/a~1b":  "slash",
+		"/list/1": "one",
+	}
+	for pointer, expected := range tests {
+		node, err := resolvePointer(root.Content[0], pointer)
+		if err != nil {
+			t.Errorf("resolvePointer(%q) returned an error: %v", pointer, err)
+		} else if node.Value != expected {
+			t.Errorf("resolvePointer(%q) = %q, expected %q", pointer, node.Value, expected)
+		}
+	}
+}
+
+func TestLintExternalRefs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeTestFile(t, tempDir, "address.json", `{"properties": {"street": {}, "city": {}}}`)
+	if err := os.Mkdir(filepath.Join(tempDir, "defs"), 0o755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	writeTestFile(t, tempDir, "defs/common.yaml", `definitions:
+  Contact:
+    properties:
+      name: {}
+      address:
+        $ref: ../address.json
+`)
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{
+  "properties": {
+    "shipping": {"$ref": "./address.json"},
+    "billing": {"$ref": "defs/common.yaml#/definitions/Contact"},
+    "tree": {"$ref": "tree.json"}
+  }
+}`)
+	writeTestFile(t, tempDir, "tree.json", `{"properties": {"value": {}, "children": {"type": "array", "items": {"$ref": "./tree.json"}}}}`)
+
+	t.Run("Referenced files are enforced", func(t *testing.T) {
+		validPath := writeTestFile(t, tempDir, "valid.yaml", `shipping:
+  street: Main St
+  city: Springfield
+billing:
+  name: Ada
+  address:
+    street: Elm St
+    city: Shelbyville
+tree:
+  value: 1
+  children:
+    - value: 2
+      children: []
+`)
+		err := Lint(validPath, schemaPath)
+		if err != nil {
+			t.Errorf("Lint() returned an error for a document following referenced files: %v", err)
+		}
+
+		invalidPath := writeTestFile(t, tempDir, "invalid.yaml", `shipping:
+  city: Springfield
+  street: Main St
+billing:
+  address:
+    city: Shelbyville
+    street: Elm St
+  name: Ada
+tree:
+  children:
+    - children: []
+      value: 2
+  value: 1
+`)
+		violations, err := lintAll(invalidPath, schemaPath, LintOptions{})
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+
+		var paths []string
+		for _, violation := range violations {
+			paths = append(paths, strings.Join(violation.Path, "."))
+		}
+		// The tree isn't checked below the point where it refers to itself
+		expected := "shipping billing billing.address tree"
+		if strings.Join(paths, " ") != expected {
+			t.Errorf("LintAll() reported violations at %v, expected %s", paths, expected)
+		}
+	})
+
+	t.Run("Broken references", func(t *testing.T) {
+		writeTestFile(t, tempDir, "a.json", `{"$ref": "b.json"}`)
+		writeTestFile(t, tempDir, "b.json", `{"$ref": "a.json#"}`)
+		validPath := writeTestFile(t, tempDir, "doc.yaml", "id: 1\n")
+
+		tests := []struct {
+			name     string
+			schema   string
+			expected string
+		}{
+			{"Missing file", `{"properties": {"id": {"$ref": "./missing.json"}}}`, `$ref "./missing.json" at line 1, column 32: open `},
+			{"Missing fragment", `{"properties": {"id": {"$ref": "address.json#/definitions/Nope"}}}`, `JSON pointer "#/definitions/Nope" not found`},
+			{"Circular references", `{"properties": {"id": {"$ref": "a.json"}}}`, `circular $ref "a.json#" at line 1, column 10 of b.json`},
+		}
+
+		for _, tt := range tests {
+			err := Lint(validPath, writeTestFile(t, tempDir, "broken.json", tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("%s: Lint() returned %v, expected an error containing %q", tt.name, err, tt.expected)
+			}
+		}
+	})
+}
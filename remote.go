@@ -0,0 +1,250 @@
+package order
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LintURL is like LintWithOptions but fetches the schema from schemaURL over HTTP. Gzipped schemas are decompressed,
+// and the schema is read as YAML when the URL path ends in .yaml or .yml. Its $refs can't point to other files.
+// Downloads are cached under LintOptions.SchemaCacheDir and revalidated with If-None-Match, unless NoCache is set
+func LintURL(yamlOrJsonPath, schemaURL string, opts LintOptions) error {
+	// Unsupported formats are reported before fetching the schema
+	if _, err := parserFor(filepath.Ext(yamlOrJsonPath)); err != nil {
+		return withPath(yamlOrJsonPath, err)
+	}
+
+	schema, err := loadSchemaURL(schemaURL, opts)
+	if err != nil {
+		return err
+	}
+	if err := checkSchemaOptions(schema, opts); err != nil {
+		return withPath(schemaURL, err)
+	}
+
+	return lintFile(yamlOrJsonPath, schemaURL, schema, opts)
+}
+
+// schemaCacheEntry is a schema download stored in the schema cache directory
+type schemaCacheEntry struct {
+	URL     string `json:"url"`
+	ETag    string `json:"etag"`
+	Content []byte `json:"content"`
+}
+
+// schemaClient downloads the schemas of LintURL, giving up on servers that don't answer in time
+var schemaClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxSchemaBytes limits the size of a downloaded schema, both as transferred and once decompressed, and of the
+// archive entries read by LintFromArchive
+var maxSchemaBytes int64 = 32 << 20
+
+// maxParsedSchemas limits the number of schemas parsedSchemas remembers
+const maxParsedSchemas = 64
+
+// parsedSchemaCache holds the schemas parsed by LintURL, keyed by their URL, ETag and pointer, so a schema the
+// server reports unchanged isn't parsed again. Past maxParsedSchemas the oldest schema is evicted. Schemas are
+// copied in and out, so callers never share a tree they could modify
+type parsedSchemaCache struct {
+	mu      sync.Mutex
+	schemas map[string]*SchemaProperty
+	keys    []string
+}
+
+// load returns a copy of the schema stored for key and whether there was one
+func (c *parsedSchemaCache) load(key string) (*SchemaProperty, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	schema, ok := c.schemas[key]
+	if !ok {
+		return nil, false
+	}
+
+	return cloneSchemaProperty(schema), true
+}
+
+// store remembers a copy of schema for key, evicting the oldest schema when full
+func (c *parsedSchemaCache) store(key string, schema *SchemaProperty) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.schemas[key]; ok {
+		return
+	}
+	if len(c.keys) >= maxParsedSchemas {
+		delete(c.schemas, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+
+	c.keys = append(c.keys, key)
+	c.schemas[key] = cloneSchemaProperty(schema)
+}
+
+var parsedSchemas = &parsedSchemaCache{schemas: make(map[string]*SchemaProperty)}
+
+// loadSchemaURL fetches the schema at schemaURL, reusing the cached download when the server reports it unchanged,
+// and parses the schema found at LintOptions.SchemaPointer
+func loadSchemaURL(schemaURL string, opts LintOptions) (*SchemaProperty, error) {
+	u, err := url.Parse(schemaURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var cachePath string
+	var cached *schemaCacheEntry
+	if !opts.NoCache {
+		dir := opts.SchemaCacheDir
+		if dir == "" {
+			userDir, err := os.UserCacheDir()
+			if err != nil {
+				return nil, err
+			}
+			dir = filepath.Join(userDir, "order", "schemas")
+		}
+
+		sum := sha256.Sum256([]byte(schemaURL))
+		cachePath = filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+		cached = readSchemaCacheEntry(cachePath, schemaURL)
+	}
+
+	entry, err := fetchSchema(schemaURL, cached)
+	if err != nil {
+		return nil, err
+	}
+	if cachePath != "" && entry != cached && entry.ETag != "" {
+		if err := writeSchemaCacheEntry(cachePath, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	key := schemaURL + "\x00" + entry.ETag + "\x00" + opts.SchemaPointer
+	if !opts.NoCache && entry.ETag != "" {
+		if schema, ok := parsedSchemas.load(key); ok {
+			return schema, nil
+		}
+	}
+
+	// A compressed schema is named after its uncompressed form, such as schema.yaml.gz
+	ext := path.Ext(u.Path)
+	if ext == ".gz" {
+		ext = path.Ext(u.Path[:len(u.Path)-len(ext)])
+	}
+
+	root, err := parseSchemaFile(entry.Content, ext)
+	if err != nil {
+		return nil, withPath(schemaURL, err)
+	}
+
+	schema, err := parseSchemaDocumentAt(root, schemaURL, opts.SchemaPointer)
+	if err != nil {
+		return nil, withPath(schemaURL, err)
+	}
+
+	if !opts.NoCache && entry.ETag != "" {
+		parsedSchemas.store(key, schema)
+	}
+
+	return schema, nil
+}
+
+// fetchSchema downloads the schema at schemaURL, returning cached unchanged when the server answers
+// If-None-Match with 304 Not Modified. cached may be nil. Schemas larger than maxSchemaBytes fail with
+// ErrInputTooLarge
+func fetchSchema(schemaURL string, cached *schemaCacheEntry) (*schemaCacheEntry, error) {
+	request, err := http.NewRequest(http.MethodGet, schemaURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && cached.ETag != "" {
+		request.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	response, err := schemaClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotModified && cached != nil:
+		return cached, nil
+	case response.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: unexpected status %s", schemaURL, response.Status)
+	}
+
+	content, err := readSchemaContent(response.Body)
+	if err != nil {
+		return nil, withPath(schemaURL, err)
+	}
+
+	// Schemas stored compressed are recognised by the gzip magic number
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, withPath(schemaURL, err)
+		}
+		if content, err = readSchemaContent(reader); err != nil {
+			return nil, withPath(schemaURL, err)
+		}
+	}
+
+	return &schemaCacheEntry{URL: schemaURL, ETag: response.Header.Get("ETag"), Content: content}, nil
+}
+
+// readSchemaContent reads all of r, failing with ErrInputTooLarge past maxSchemaBytes
+func readSchemaContent(r io.Reader) ([]byte, error) {
+	// Read one byte past the limit to tell a schema of exactly maxSchemaBytes from a larger one
+	content, err := io.ReadAll(io.LimitReader(r, maxSchemaBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSchemaBytes {
+		return nil, fmt.Errorf("schema larger than %d bytes: %w", maxSchemaBytes, ErrInputTooLarge)
+	}
+
+	return content, nil
+}
+
+// readSchemaCacheEntry reads the cached download of schemaURL at path. A missing or unreadable entry
+// is treated as not cached, so it is downloaded again
+func readSchemaCacheEntry(path, schemaURL string) *schemaCacheEntry {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var entry schemaCacheEntry
+	if json.Unmarshal(content, &entry) != nil || entry.URL != schemaURL {
+		return nil
+	}
+
+	return &entry
+}
+
+// writeSchemaCacheEntry stores entry at path, replacing the previous entry atomically so concurrent runs
+// never read a partial one
+func writeSchemaCacheEntry(path string, entry *schemaCacheEntry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, content, 0o600)
+}
@@ -0,0 +1,178 @@
+package order
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLintURL(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write([]byte("properties:\n  first: {}\n  second: {}\n"))
+	writer.Close()
+
+	// Compresses to far less than it expands to
+	var bomb bytes.Buffer
+	writer = gzip.NewWriter(&bomb)
+	writer.Write([]byte(`{"properties": {"first": {"description": "` + strings.Repeat("a", 1<<20) + `"}}}`))
+	writer.Close()
+
+	var mu sync.Mutex
+	var revalidations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		revalidations = append(revalidations, r.Header.Get("If-None-Match"))
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/schema.json":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Write([]byte(`{"properties": {"first": {}, "second": {}}}`))
+		case "/schema.yaml.gz":
+			w.Write(gzipped.Bytes())
+		case "/large.json":
+			w.Write([]byte(`{"properties": {"first": {"description": "` + strings.Repeat("a", 1<<20) + `"}}}`))
+		case "/bomb.json.gz":
+			w.Write(bomb.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	validPath := writeTestFile(t, tempDir, "valid.yaml", "first: 1\nsecond: 2\n")
+	invalidPath := writeTestFile(t, tempDir, "invalid.yaml", "second: 2\nfirst: 1\n")
+
+	requests := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		got := revalidations
+		revalidations = nil
+		return got
+	}
+
+	t.Run("Cached and revalidated", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		opts := LintOptions{SchemaCacheDir: cacheDir}
+
+		if err := LintURL(validPath, server.URL+"/schema.json", opts); err != nil {
+			t.Errorf("LintURL() returned an error for a valid file: %v", err)
+		}
+
+		err := LintURL(invalidPath, server.URL+"/schema.json", opts)
+		var violation *Violation
+		if !errors.As(err, &violation) || violation.Key != "second" || violation.Schema != server.URL+"/schema.json" {
+			t.Errorf("LintURL() returned unexpected error for an invalid file: %v", err)
+		}
+
+		got := requests()
+		if len(got) != 2 || got[0] != "" || got[1] != `"v1"` {
+			t.Errorf("LintURL() sent If-None-Match headers %q, expected none and then the cached ETag", got)
+		}
+
+		entries, err := os.ReadDir(cacheDir)
+		if err != nil || len(entries) != 1 {
+			t.Errorf("LintURL() left %d entries in the cache directory, expected 1: %v", len(entries), err)
+		}
+	})
+
+	t.Run("NoCache", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		opts := LintOptions{SchemaCacheDir: cacheDir, NoCache: true}
+
+		for i := 0; i < 2; i++ {
+			if err := LintURL(validPath, server.URL+"/schema.json", opts); err != nil {
+				t.Errorf("LintURL() returned an error for a valid file: %v", err)
+			}
+		}
+
+		if got := requests(); len(got) != 2 || got[0] != "" || got[1] != "" {
+			t.Errorf("LintURL() sent If-None-Match headers %q with NoCache", got)
+		}
+		if entries, _ := os.ReadDir(cacheDir); len(entries) != 0 {
+			t.Errorf("LintURL() wrote %d entries to the cache directory with NoCache", len(entries))
+		}
+	})
+
+	t.Run("Gzipped YAML schema", func(t *testing.T) {
+		err := LintURL(invalidPath, server.URL+"/schema.yaml.gz", LintOptions{SchemaCacheDir: t.TempDir()})
+		var violation *Violation
+		if !errors.As(err, &violation) || violation.Key != "second" {
+			t.Errorf("LintURL() returned unexpected error for an invalid file: %v", err)
+		}
+		requests()
+	})
+
+	t.Run("Oversized schema", func(t *testing.T) {
+		defer func(limit int64) { maxSchemaBytes = limit }(maxSchemaBytes)
+		maxSchemaBytes = 64 << 10
+
+		for _, name := range []string{"/large.json", "/bomb.json.gz"} {
+			err := LintURL(validPath, server.URL+name, LintOptions{NoCache: true})
+			if !errors.Is(err, ErrInputTooLarge) {
+				t.Errorf("LintURL() returned unexpected error for %s: %v", name, err)
+			}
+		}
+		requests()
+	})
+
+	t.Run("Parsed schemas are copied", func(t *testing.T) {
+		opts := LintOptions{SchemaCacheDir: t.TempDir()}
+
+		schema, err := loadSchemaURL(server.URL+"/schema.json", opts)
+		if err != nil {
+			t.Fatalf("loadSchemaURL() returned an error: %v", err)
+		}
+		schema.Properties[0].Name = "changed"
+
+		again, err := loadSchemaURL(server.URL+"/schema.json", opts)
+		if err != nil {
+			t.Fatalf("loadSchemaURL() returned an error: %v", err)
+		}
+		if again.Properties[0].Name != "first" {
+			t.Errorf("loadSchemaURL() returned a schema modified by a previous caller: %q", again.Properties[0].Name)
+		}
+		requests()
+	})
+
+	t.Run("Missing schema", func(t *testing.T) {
+		err := LintURL(validPath, server.URL+"/missing.json", LintOptions{SchemaCacheDir: t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), "/missing.json: unexpected status 404 Not Found") {
+			t.Errorf("LintURL() returned unexpected error for a missing schema: %v", err)
+		}
+		requests()
+	})
+}
+
+func TestParsedSchemaCache(t *testing.T) {
+	cache := &parsedSchemaCache{schemas: make(map[string]*SchemaProperty)}
+
+	for i := 0; i <= maxParsedSchemas; i++ {
+		cache.store(strconv.Itoa(i), &SchemaProperty{Name: strconv.Itoa(i)})
+	}
+
+	if _, ok := cache.load("0"); ok {
+		t.Errorf("load() returned the oldest schema past maxParsedSchemas")
+	}
+	if schema, ok := cache.load(strconv.Itoa(maxParsedSchemas)); !ok || schema.Name != strconv.Itoa(maxParsedSchemas) {
+		t.Errorf("load() returned %v, %t for the newest schema", schema, ok)
+	}
+	if len(cache.schemas) != maxParsedSchemas {
+		t.Errorf("parsedSchemaCache holds %d schemas, expected %d", len(cache.schemas), maxParsedSchemas)
+	}
+}
@@ -0,0 +1,203 @@
+package order
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Report holds the outcome of linting a single file
+type Report struct {
+	// File is the path of the linted document
+	File string
+	// Schema is the path of the schema the document was validated against
+	Schema string
+	// SchemaSource is Schema followed by the JSON pointer selecting the schema inside it, like Violation.SchemaSource
+	SchemaSource string
+	// Violations lists every problem found, it is empty when the file is valid
+	Violations []Violation
+	// Truncated counts the violations found past LintOptions.MaxViolations, which are left out of Violations.
+	// Zero means Violations is complete
+	Truncated int
+	// Moves lists the keys reordered by FixReport
+	Moves []Move
+	// CheckedPaths lists the dotted paths of the keys checked against a schema property, such as spec.ports[0].name,
+	// in document order. It is only filled under LintOptions.RecordCheckedPaths
+	CheckedPaths []string
+	// Skipped is set when the document didn't opt into validation under LintOptions.RequireOptInKey
+	Skipped bool
+}
+
+// LintReport lints a file like LintAll, collecting the outcome in a Report.
+// Violations past LintOptions.MaxViolations are only counted in Report.Truncated, the keys checked are listed
+// in Report.CheckedPaths under LintOptions.RecordCheckedPaths, and documents skipped under
+// LintOptions.RequireOptInKey are marked Report.Skipped
+func LintReport(yamlOrJsonPath, jsonSchemaPath string, opts LintOptions) (Report, error) {
+	var checkedPaths []string
+	if opts.RecordCheckedPaths {
+		opts.checked = func(path string) {
+			checkedPaths = append(checkedPaths, path)
+		}
+	}
+
+	skipped := false
+	opts.skipped = func() {
+		skipped = true
+	}
+
+	var violations []Violation
+	truncated := 0
+	err := lintVisit(yamlOrJsonPath, jsonSchemaPath, opts, func(violation Violation) bool {
+		if opts.MaxViolations > 0 && len(violations) == opts.MaxViolations {
+			truncated++
+		} else {
+			violations = append(violations, violation)
+		}
+		return true
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	file := relativePath(yamlOrJsonPath, opts.BaseDir)
+	for i := range violations {
+		violations[i].File = file
+	}
+
+	return Report{
+		File:         file,
+		Schema:       jsonSchemaPath,
+		SchemaSource: schemaSource(jsonSchemaPath, opts.SchemaPointer),
+		Violations:   violations,
+		Truncated:    truncated,
+		CheckedPaths: checkedPaths,
+		Skipped:      skipped,
+	}, nil
+}
+
+// relativePath returns path relative to baseDir, or path unchanged when baseDir is empty or path can't be made relative
+func relativePath(path, baseDir string) string {
+	if baseDir == "" {
+		return path
+	}
+
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		// Rel needs both paths to be absolute or both relative
+		absBase, errBase := filepath.Abs(baseDir)
+		absPath, errPath := filepath.Abs(path)
+		if errBase != nil || errPath != nil {
+			return path
+		}
+		if rel, err = filepath.Rel(absBase, absPath); err != nil {
+			return path
+		}
+	}
+
+	return rel
+}
+
+// LintFiles lints every file of paths against the schema at jsonSchemaPath, returning a report per file
+func LintFiles(paths []string, jsonSchemaPath string) ([]Report, error) {
+	schema, err := loadSchema(jsonSchemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []Report
+	for _, path := range paths {
+		violations, err := lintFileAll(path, jsonSchemaPath, schema, LintOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, Report{File: path, Schema: jsonSchemaPath, SchemaSource: jsonSchemaPath, Violations: violations})
+	}
+
+	return reports, nil
+}
+
+// LintFilesErr lints every file of paths like Lint, joining the error of each failing file, prefixed with its path,
+// with errors.Join. It returns nil when every file passes
+func LintFilesErr(paths []string, jsonSchemaPath string) error {
+	schema, err := loadSchema(jsonSchemaPath)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, path := range paths {
+		if err := lintFile(path, jsonSchemaPath, schema, LintOptions{}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// PatternRule selects the schema used for files whose name matches Glob, using filepath.Match syntax
+type PatternRule struct {
+	Glob   string
+	Schema string
+}
+
+// LintByPattern lints every file directly inside dir against the schema of the first rule whose glob matches
+// the file name, returning a report per linted file. Files matching no rule are skipped
+func LintByPattern(dir string, rules []PatternRule) ([]Report, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each schema only needs to be read once
+	schemas := make(map[string]*SchemaProperty)
+
+	var reports []Report
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		rule, ok, err := matchPatternRule(rules, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		schema, ok := schemas[rule.Schema]
+		if !ok {
+			schema, err = loadSchema(rule.Schema)
+			if err != nil {
+				return nil, err
+			}
+			schemas[rule.Schema] = schema
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		violations, err := lintFileAll(path, rule.Schema, schema, LintOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, Report{File: path, Schema: rule.Schema, SchemaSource: rule.Schema, Violations: violations})
+	}
+
+	return reports, nil
+}
+
+// matchPatternRule returns the first rule whose glob matches name
+func matchPatternRule(rules []PatternRule, name string) (PatternRule, bool, error) {
+	for _, rule := range rules {
+		matched, err := filepath.Match(rule.Glob, name)
+		if err != nil {
+			return PatternRule{}, false, err
+		}
+		if matched {
+			return rule, true, nil
+		}
+	}
+
+	return PatternRule{}, false, nil
+}
@@ -0,0 +1,216 @@
+package order
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLintReport(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"first": {}, "second": {}}}`)
+	invalidPath := writeTestFile(t, tempDir, "invalid.yaml", "second: 2\nfirst: 1\n")
+
+	report, err := LintReport(invalidPath, schemaPath, LintOptions{})
+	if err != nil {
+		t.Fatalf("LintReport() returned an error: %v", err)
+	}
+
+	if report.File != invalidPath || report.Schema != schemaPath || len(report.Violations) != 1 {
+		t.Errorf("LintReport() returned unexpected report: %+v", report)
+	}
+
+	t.Run("Relative to BaseDir", func(t *testing.T) {
+		report, err := LintReport(invalidPath, schemaPath, LintOptions{BaseDir: filepath.Dir(tempDir)})
+		if err != nil {
+			t.Fatalf("LintReport() returned an error: %v", err)
+		}
+
+		expected := filepath.Join(filepath.Base(tempDir), "invalid.yaml")
+		if report.File != expected {
+			t.Errorf("LintReport() returned file %q, expected %q", report.File, expected)
+		}
+	})
+
+	t.Run("Truncated to MaxViolations", func(t *testing.T) {
+		wideSchemaPath := writeTestFile(t, tempDir, "wide.json", `{"properties": {"a": {}, "b": {}, "c": {}, "d": {}, "e": {}}}`)
+		reversedPath := writeTestFile(t, tempDir, "reversed.yaml", "e: 1\nd: 1\nc: 1\nb: 1\na: 1\n")
+		opts := LintOptions{MaxViolations: 2}
+
+		report, err := LintReport(reversedPath, wideSchemaPath, opts)
+		if err != nil {
+			t.Fatalf("LintReport() returned an error: %v", err)
+		}
+		if len(report.Violations) != 2 || report.Violations[1].Key != "d" || report.Truncated != 2 {
+			t.Errorf("LintReport() returned %d violations with %d truncated, expected 2 and 2: %+v",
+				len(report.Violations), report.Truncated, report.Violations)
+		}
+
+		result, err := LintAll(reversedPath, wideSchemaPath, opts)
+		if err != nil {
+			t.Fatalf("LintAll() returned an error: %v", err)
+		}
+		if len(result.Violations) != 2 || result.Truncated != 2 {
+			t.Errorf("LintAll() returned %d violations with %d truncated, expected 2 and 2", len(result.Violations), result.Truncated)
+		}
+
+		expected := reversedPath + `: 2 violations
+  1:1: properties out of order: 'e' should come after 'd' according to the schema
+  2:1: properties out of order: 'd' should come after 'c' according to the schema
+  ... and 2 more`
+		if result.Error() != expected {
+			t.Errorf("LintResult.Error() returned\n%s\nexpected\n%s", result.Error(), expected)
+		}
+
+		report, err = LintReport(reversedPath, wideSchemaPath, LintOptions{MaxViolations: 4})
+		if err != nil || len(report.Violations) != 4 || report.Truncated != 0 {
+			t.Errorf("LintReport() truncated a report within MaxViolations: %+v, %v", report, err)
+		}
+	})
+
+	t.Run("Checked paths", func(t *testing.T) {
+		nestedSchemaPath := writeTestFile(t, tempDir, "nested.json", `{
+  "properties": {
+    "name": {},
+    "spec": {"properties": {"replicas": {}, "ports": {"items": {"properties": {"name": {}, "port": {}}}}}}
+  }
+}`)
+		documentPath := writeTestFile(t, tempDir, "nested.yaml", `name: web
+x-note: ignored
+spec:
+  replicas: 2
+  image: nginx
+  ports:
+    - name: http
+      port: 80
+    - port: 443
+`)
+
+		report, err := LintReport(documentPath, nestedSchemaPath, LintOptions{RecordCheckedPaths: true, IgnorePrefixes: []string{"x-"}})
+		if err != nil {
+			t.Fatalf("LintReport() returned an error: %v", err)
+		}
+
+		expected := []string{"name", "spec", "spec.replicas", "spec.ports", "spec.ports[0].name", "spec.ports[0].port", "spec.ports[1].port"}
+		if !reflect.DeepEqual(report.CheckedPaths, expected) {
+			t.Errorf("LintReport() returned checked paths %v, expected %v", report.CheckedPaths, expected)
+		}
+
+		report, err = LintReport(documentPath, nestedSchemaPath, LintOptions{RecordCheckedPaths: true, MaxDepth: 1})
+		if err != nil || !reflect.DeepEqual(report.CheckedPaths, []string{"name", "spec"}) {
+			t.Errorf("LintReport() returned checked paths %v under MaxDepth, expected [name spec]: %v", report.CheckedPaths, err)
+		}
+
+		report, err = LintReport(documentPath, nestedSchemaPath, LintOptions{})
+		if err != nil || report.CheckedPaths != nil {
+			t.Errorf("LintReport() returned checked paths %v without RecordCheckedPaths: %v", report.CheckedPaths, err)
+		}
+	})
+}
+
+func TestLintFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"first": {}, "second": {}}}`)
+	validPath := writeTestFile(t, tempDir, "valid.yaml", "first: 1\nsecond: 2\n")
+	invalidPath := writeTestFile(t, tempDir, "invalid.json", `{"second": 2, "first": 1}`)
+	missingPath := filepath.Join(tempDir, "missing.yaml")
+
+	t.Run("Reports", func(t *testing.T) {
+		reports, err := LintFiles([]string{validPath, invalidPath}, schemaPath)
+		if err != nil {
+			t.Fatalf("LintFiles() returned an error: %v", err)
+		}
+		if len(reports) != 2 || len(reports[0].Violations) != 0 || len(reports[1].Violations) != 1 || reports[1].File != invalidPath {
+			t.Errorf("LintFiles() returned unexpected reports: %+v", reports)
+		}
+	})
+
+	t.Run("Joined errors", func(t *testing.T) {
+		err := LintFilesErr([]string{validPath, invalidPath, missingPath}, schemaPath)
+		if err == nil {
+			t.Fatalf("LintFilesErr() did not return an error for failing files")
+		}
+
+		var violation *Violation
+		if !errors.As(err, &violation) || violation.File != invalidPath {
+			t.Errorf("LintFilesErr() returned an error without the violation of %s: %v", invalidPath, err)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("LintFilesErr() returned an error without the missing file: %v", err)
+		}
+
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[0], invalidPath+": ") || !strings.Contains(lines[1], missingPath) {
+			t.Errorf("LintFilesErr() returned errors not naming their files: %v", err)
+		}
+	})
+
+	t.Run("Every file passes", func(t *testing.T) {
+		if err := LintFilesErr([]string{validPath, validPath}, schemaPath); err != nil {
+			t.Errorf("LintFilesErr() returned an error for valid files: %v", err)
+		}
+		if err := LintFilesErr(nil, schemaPath); err != nil {
+			t.Errorf("LintFilesErr() returned an error without files: %v", err)
+		}
+	})
+}
+
+func TestLintByPattern(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, "configs")
+	if err := os.Mkdir(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+
+	serviceSchemaPath := writeTestFile(t, tempDir, "service.json", `{"properties": {"name": {}, "port": {}}}`)
+	jobSchemaPath := writeTestFile(t, tempDir, "job.json", `{"properties": {"name": {}, "schedule": {}}}`)
+
+	writeTestFile(t, configDir, "api.service.yaml", "name: api\nport: 80\n")
+	writeTestFile(t, configDir, "web.service.yaml", "port: 80\nname: web\n")
+	writeTestFile(t, configDir, "backup.job.yaml", "schedule: daily\nname: backup\n")
+	writeTestFile(t, configDir, "notes.txt", "not linted")
+
+	rules := []PatternRule{
+		{Glob: "*.service.yaml", Schema: serviceSchemaPath},
+		{Glob: "*.job.yaml", Schema: jobSchemaPath},
+	}
+
+	reports, err := LintByPattern(configDir, rules)
+	if err != nil {
+		t.Fatalf("LintByPattern() returned an error: %v", err)
+	}
+
+	expected := map[string]struct {
+		schema     string
+		violations int
+	}{
+		"api.service.yaml": {serviceSchemaPath, 0},
+		"web.service.yaml": {serviceSchemaPath, 1},
+		"backup.job.yaml":  {jobSchemaPath, 1},
+	}
+	if len(reports) != len(expected) {
+		t.Fatalf("LintByPattern() returned %d reports, expected %d: %+v", len(reports), len(expected), reports)
+	}
+
+	for _, report := range reports {
+		want, ok := expected[filepath.Base(report.File)]
+		if !ok {
+			t.Errorf("LintByPattern() linted an unexpected file: %s", report.File)
+			continue
+		}
+		if report.Schema != want.schema || len(report.Violations) != want.violations {
+			t.Errorf("LintByPattern() returned unexpected report for %s: %+v", report.File, report)
+		}
+	}
+
+	_, err = LintByPattern(configDir, []PatternRule{{Glob: "[", Schema: serviceSchemaPath}})
+	if err == nil {
+		t.Errorf("LintByPattern() did not return an error for a malformed glob")
+	}
+}
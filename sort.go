@@ -0,0 +1,81 @@
+package order
+
+import (
+	"strings"
+
+	"golang.org/x/text/collate"
+)
+
+// SortOptions configures how keys of objects ordered with "x-order": "alphabetical" are compared
+type SortOptions struct {
+	// Numeric compares runs of digits within keys by their value, so "item2" sorts before "item10"
+	Numeric bool
+
+	// Collator compares keys by the collation rules of a language, such as collate.New(language.German), so "äpfel"
+	// sorts between "apfel" and "birne" instead of after "zebra". It takes precedence over Numeric, which
+	// the collate.Numeric option replaces. Nil compares keys byte by byte. A Collator can't be shared by
+	// concurrent lints
+	Collator *collate.Collator
+}
+
+// less reports whether key a sorts before key b
+func (o SortOptions) less(a, b string) bool {
+	if o.Collator != nil {
+		return o.Collator.CompareString(a, b) < 0
+	}
+	if !o.Numeric {
+		return a < b
+	}
+
+	return naturalCompare(a, b) < 0
+}
+
+// naturalCompare compares a and b by splitting them into digit and text runs, comparing digit runs by value.
+// Keys whose runs are all equal, such as "a01" and "a1", fall back to a lexical comparison
+func naturalCompare(a, b string) int {
+	restA, restB := a, b
+	for restA != "" && restB != "" {
+		runA, digitsA := nextRun(restA)
+		runB, digitsB := nextRun(restB)
+		restA, restB = restA[len(runA):], restB[len(runB):]
+
+		if digitsA && digitsB {
+			valueA, valueB := strings.TrimLeft(runA, "0"), strings.TrimLeft(runB, "0")
+			// Without leading zeros the longer number is the larger one
+			if len(valueA) != len(valueB) {
+				return len(valueA) - len(valueB)
+			}
+			if c := strings.Compare(valueA, valueB); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		if c := strings.Compare(runA, runB); c != 0 {
+			return c
+		}
+	}
+
+	if restA != "" || restB != "" {
+		return len(restA) - len(restB)
+	}
+
+	return strings.Compare(a, b)
+}
+
+// nextRun returns the leading run of s made only of digits or only of other characters,
+// and whether it is a digit run
+func nextRun(s string) (string, bool) {
+	digits := isDigit(s[0])
+	end := 1
+	for end < len(s) && isDigit(s[end]) == digits {
+		end++
+	}
+
+	return s[:end], digits
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,83 @@
+package order
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"item2", "item10", -1},
+		{"10", "2", 1},
+		{"item10", "item10", 0},
+		{"item", "item1", -1},
+		{"a1b2", "a1b10", -1},
+		{"a01", "a1", -1},
+		{"v1.2.10", "v1.10.2", -1},
+		{"b", "a10", 1},
+	}
+
+	for _, tt := range tests {
+		c := naturalCompare(tt.a, tt.b)
+		if (c < 0 && tt.expected >= 0) || (c > 0 && tt.expected <= 0) || (c == 0 && tt.expected != 0) {
+			t.Errorf("naturalCompare(%q, %q) = %d, expected the sign of %d", tt.a, tt.b, c, tt.expected)
+		}
+	}
+}
+
+func TestLintNumericSort(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"items": {"x-order": "alphabetical"}}}`)
+	path := writeTestFile(t, tempDir, "items.yaml", "items:\n  item1: a\n  item2: b\n  item10: c\n")
+
+	err := LintWithOptions(path, schemaPath, LintOptions{Sort: SortOptions{Numeric: true}})
+	if err != nil {
+		t.Errorf("LintWithOptions() returned an error for numerically sorted keys: %v", err)
+	}
+
+	err = Lint(path, schemaPath)
+	if err == nil || !strings.Contains(err.Error(), "'item2' should come after 'item10'") {
+		t.Errorf("Lint() did not return the expected error for lexically unsorted keys: %v", err)
+	}
+
+	fixed, err := FixBytes([]byte("items:\n  item10: c\n  item2: b\n"), ".yaml", schemaPath, FixOptions{Sort: SortOptions{Numeric: true}})
+	if err != nil {
+		t.Fatalf("FixBytes() returned an error: %v", err)
+	}
+	if expected := "items:\n  item2: b\n  item10: c\n"; string(fixed) != expected {
+		t.Errorf("FixBytes() returned %q, expected %q", fixed, expected)
+	}
+}
+
+func TestLintCollatedSort(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"fruits": {"x-order": "alphabetical"}}}`)
+	path := writeTestFile(t, tempDir, "fruits.yaml", "fruits:\n  apfel: 1\n  äpfel: 2\n  birne: 3\n  zitrone: 4\n")
+
+	err := LintWithOptions(path, schemaPath, LintOptions{Sort: SortOptions{Collator: collate.New(language.German)}})
+	if err != nil {
+		t.Errorf("LintWithOptions() returned an error for keys sorted by German collation: %v", err)
+	}
+
+	err = Lint(path, schemaPath)
+	if err == nil || !strings.Contains(err.Error(), "'äpfel' should come after 'birne'") {
+		t.Errorf("Lint() did not return the expected error for keys out of byte order: %v", err)
+	}
+
+	bytePath := writeTestFile(t, tempDir, "bytes.yaml", "fruits:\n  apfel: 1\n  birne: 2\n  zitrone: 3\n  äpfel: 4\n")
+	if err := Lint(bytePath, schemaPath); err != nil {
+		t.Errorf("Lint() returned an error for keys in byte order: %v", err)
+	}
+	err = LintWithOptions(bytePath, schemaPath, LintOptions{Sort: SortOptions{Collator: collate.New(language.German)}})
+	if err == nil || !strings.Contains(err.Error(), "'birne' should come after 'äpfel'") {
+		t.Errorf("LintWithOptions() did not return the expected error for keys out of German collation: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package order
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// checkUnknownKeysSorted reports the keys of a mapping, found at path, that the schema doesn't list when they
+// precede a key it lists or a key it doesn't list that sorts before them, returning whether validation should
+// continue. keys holds the names matched against propertiesByName and keyNodes their nodes
+func (v *validator) checkUnknownKeysSorted(path []string, keys []string, keyNodes []*yaml.Node,
+	propertiesByName map[string]*SchemaProperty) bool {
+	lastKnown := -1
+	for i, key := range keys {
+		if _, ok := propertiesByName[key]; ok {
+			lastKnown = i
+		}
+	}
+
+	for i, key := range keys {
+		if _, ok := propertiesByName[key]; ok {
+			continue
+		}
+
+		if i < lastKnown {
+			if !v.report(path, keyNodes[i],
+				"properties out of order: '"+keyNodes[i].Value+"' should come after '"+keyNodes[lastKnown].Value+
+					"', keys missing from the schema follow the keys it lists") {
+				return false
+			}
+			continue
+		}
+
+		for j := i + 1; j < len(keys); j++ {
+			if v.opts.Sort.less(keys[j], key) {
+				if !v.report(path, keyNodes[i],
+					"properties out of order: '"+keyNodes[i].Value+"' should come after '"+keyNodes[j].Value+
+						"' alphabetically, as keys missing from the schema are sorted") {
+					return false
+				}
+				break
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,75 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintUnknownKeysSorted(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json",
+		`{"properties": {"name": {}, "spec": {"properties": {"replicas": {}}}, "labels": {"type": "object"}}}`)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:    "Known keys first, then the rest sorted",
+			content: "name: web\nspec:\n  replicas: 2\n  alpha: 1\n  beta: 2\nannotations: {}\nzone: eu\n",
+		},
+		{
+			name:    "Free-form objects aren't checked",
+			content: "name: web\nlabels:\n  tier: web\n  app: api\n",
+		},
+		{
+			name:    "Unknown key before a known key",
+			content: "name: web\nzone: eu\nspec:\n  replicas: 2\n",
+			expected: []string{
+				"properties out of order: 'zone' should come after 'spec', keys missing from the schema follow the keys it lists",
+			},
+		},
+		{
+			name:    "Unknown keys unsorted",
+			content: "name: web\nspec:\n  replicas: 2\n  beta: 2\n  alpha: 1\n",
+			expected: []string{
+				"in property 'spec': properties out of order: 'beta' should come after 'alpha' alphabetically, as keys missing from the schema are sorted",
+			},
+		},
+		{
+			name:    "Known keys out of schema order",
+			content: "spec:\n  replicas: 2\nname: web\nzone: eu\n",
+			expected: []string{
+				"properties out of order: 'spec' should come after 'name' according to the schema",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documentPath := writeTestFile(t, tempDir, "document.yaml", tt.content)
+
+			violations, err := lintAll(documentPath, schemaPath, LintOptions{UnknownKeysSorted: true})
+			if err != nil {
+				t.Fatalf("LintAll() returned an error: %v", err)
+			}
+
+			var got []string
+			for _, violation := range violations {
+				got = append(got, violation.Error())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("LintAll() returned %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Not checked by default", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "default.yaml", "zone: eu\nname: web\n")
+		if err := Lint(documentPath, schemaPath); err != nil {
+			t.Errorf("Lint() checked unknown keys without UnknownKeysSorted: %v", err)
+		}
+	})
+}
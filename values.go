@@ -0,0 +1,39 @@
+package order
+
+import "gopkg.in/yaml.v3"
+
+// checkNonEmptyValues reports every key of node and its descendants holding an empty string, mapping or sequence,
+// returning whether validation should continue. Null values aren't reported
+func (v *validator) checkNonEmptyValues(node *yaml.Node, path []string) bool {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], resolveAlias(node.Content[i+1])
+
+			empty := ""
+			switch {
+			case valueNode.Kind == yaml.ScalarNode && valueNode.Value == "" && valueNode.ShortTag() == "!!str":
+				empty = "string"
+			case valueNode.Kind == yaml.MappingNode && len(valueNode.Content) == 0:
+				empty = "mapping"
+			case valueNode.Kind == yaml.SequenceNode && len(valueNode.Content) == 0:
+				empty = "sequence"
+			}
+			if empty != "" && !v.report(path, keyNode, "empty value: '"+keyNode.Value+"' holds an empty "+empty) {
+				return false
+			}
+
+			if !v.checkNonEmptyValues(node.Content[i+1], appendPath(path, keyNode.Value)) {
+				return false
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if !v.checkNonEmptyValues(item, indexPath(path, i)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
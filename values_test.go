@@ -0,0 +1,65 @@
+package order
+
+import "testing"
+
+func TestLintRequireNonEmptyValues(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"name": {}, "spec": {}}}`)
+	opts := LintOptions{RequireNonEmptyValues: true}
+
+	t.Run("Filled values", func(t *testing.T) {
+		documentPath := writeTestFile(t, tempDir, "valid.yaml", "name: web\ntimeout:\nspec:\n  ports: [80]\n  env: {DEBUG: \"0\"}\n")
+		if err := LintWithOptions(documentPath, schemaPath, opts); err != nil {
+			t.Errorf("LintWithOptions() returned an error for filled values: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name         string
+		content      string
+		expected     string
+		expectedLine int
+	}{
+		{
+			name:         "Empty scalar",
+			content:      "name: \"\"\nspec: {image: nginx}\n",
+			expected:     "empty value: 'name' holds an empty string",
+			expectedLine: 1,
+		},
+		{
+			name:         "Empty map",
+			content:      "name: web\nspec:\n  env: {}\n",
+			expected:     "in property 'spec': empty value: 'env' holds an empty mapping",
+			expectedLine: 3,
+		},
+		{
+			name:         "Empty sequence",
+			content:      `{"name": "web", "spec": {"containers": [{"ports": []}]}}`,
+			expected:     "in property 'spec': in property 'containers[0]': empty value: 'ports' holds an empty sequence",
+			expectedLine: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := ".yaml"
+			if tt.content[0] == '{' {
+				ext = ".json"
+			}
+			documentPath := writeTestFile(t, tempDir, "invalid"+ext, tt.content)
+
+			violations, err := lintAll(documentPath, schemaPath, opts)
+			if err != nil {
+				t.Fatalf("LintAll() returned an error: %v", err)
+			}
+			if len(violations) != 1 || violations[0].Error() != tt.expected || violations[0].Line != tt.expectedLine {
+				t.Errorf("LintAll() returned %+v, expected %q at line %d", violations, tt.expected, tt.expectedLine)
+			}
+
+			if err := Lint(documentPath, schemaPath); err != nil {
+				t.Errorf("Lint() returned an error without RequireNonEmptyValues: %v", err)
+			}
+		})
+	}
+}
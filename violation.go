@@ -0,0 +1,177 @@
+package order
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Severity tells how serious a violation is
+type Severity int
+
+const (
+	// SeverityError marks violations that make linting fail
+	SeverityError Severity = iota
+	// SeverityWarning marks violations that are reported without failing linting
+	SeverityWarning
+)
+
+// String returns the lowercase name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+
+	return "unknown"
+}
+
+// Violation describes a single ordering problem found in a document
+type Violation struct {
+	// Path lists the keys leading from the root of the document to the mapping holding Key
+	Path []string
+	// Key is the property reported as out of place
+	Key string
+	// Line and Column locate Key in the document, both starting at 1
+	Line   int
+	Column int
+	// Message explains the problem
+	Message string
+	// Severity tells whether the violation fails linting, the zero value being SeverityError
+	Severity Severity
+	// Snippet holds the source lines around Key when LintOptions.ShowSource is set
+	Snippet string
+	// File and Schema are the paths of the linted document and of the schema it was validated against,
+	// empty when linting content that wasn't read from a file
+	File   string
+	Schema string
+	// SchemaSource names the schema whose order was enforced, the Schema path followed by LintOptions.SchemaPointer
+	// as a URI fragment when one selected it, such as openapi.yaml#/components/schemas/Order
+	SchemaSource string
+}
+
+// Error formats the violation, prefixing the message with the properties it is nested in
+func (v Violation) Error() string {
+	var b strings.Builder
+	for _, key := range v.Path {
+		b.WriteString("in property '" + key + "': ")
+	}
+	b.WriteString(v.Message)
+
+	if v.Snippet != "" {
+		b.WriteString("\n" + v.Snippet)
+	}
+
+	return b.String()
+}
+
+// LintResult holds every violation LintAll found in the document at Path. As an error, it lists them all
+type LintResult struct {
+	Path       string
+	Violations []Violation
+
+	// Truncated counts the violations found past LintOptions.MaxViolations, which are left out of Violations.
+	// Zero means Violations is complete
+	Truncated int
+
+	// Skipped is set when the document didn't opt into validation under LintOptions.RequireOptInKey
+	Skipped bool
+}
+
+// OK reports whether the document passed, holding no violation but warnings
+func (r *LintResult) OK() bool {
+	for _, violation := range r.Violations {
+		if violation.Severity != SeverityWarning {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Error formats the violations as a block headed by the path and their count, one violation per line,
+// prefixed with its position and its severity when it is a warning. Violations left out under
+// LintOptions.MaxViolations are counted on a last line
+func (r *LintResult) Error() string {
+	var b strings.Builder
+	if r.Path != "" {
+		b.WriteString(r.Path + ": ")
+	}
+	if len(r.Violations) == 1 {
+		b.WriteString("1 violation")
+	} else {
+		b.WriteString(strconv.Itoa(len(r.Violations)) + " violations")
+	}
+
+	for _, violation := range r.Violations {
+		b.WriteString("\n  ")
+		if violation.Line > 0 {
+			fmt.Fprintf(&b, "%d:%d: ", violation.Line, violation.Column)
+		}
+		if violation.Severity == SeverityWarning {
+			b.WriteString("warning: ")
+		}
+		// Snippets are indented under their violation
+		b.WriteString(strings.ReplaceAll(violation.Error(), "\n", "\n    "))
+	}
+	if r.Truncated > 0 {
+		b.WriteString("\n  " + truncatedMessage(r.Truncated))
+	}
+
+	return b.String()
+}
+
+// truncatedMessage notes the number of violations left out of a truncated list
+func truncatedMessage(truncated int) string {
+	return "... and " + strconv.Itoa(truncated) + " more"
+}
+
+// sourceSnippet renders the lines surrounding line from content with a caret under column
+func sourceSnippet(content []byte, line, column int) string {
+	lines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	first := max(line-1, 1)
+	last := min(line+1, len(lines))
+	// Don't show the empty line following a trailing newline
+	if last > line && last == len(lines) && lines[last-1] == "" {
+		last--
+	}
+	width := len(strconv.Itoa(last))
+
+	var b strings.Builder
+	for n := first; n <= last; n++ {
+		text := strings.TrimRight(lines[n-1], "\r")
+
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		if n > first {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s %*d | %s", marker, width, n, text)
+
+		if n == line {
+			// Keep tabs so the caret lines up with the key
+			var indent strings.Builder
+			for i, r := range []rune(text) {
+				if i >= column-1 {
+					break
+				}
+				if r == '\t' {
+					indent.WriteRune('\t')
+				} else {
+					indent.WriteRune(' ')
+				}
+			}
+			fmt.Fprintf(&b, "\n  %*s | %s^", width, "", indent.String())
+		}
+	}
+
+	return b.String()
+}
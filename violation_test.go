@@ -0,0 +1,90 @@
+package order
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestShowSource(t *testing.T) {
+	tempDir := t.TempDir()
+
+	schemaPath := writeTestFile(t, tempDir, "schema.json", `{"properties": {"name": {}, "version": {}, "description": {}}}`)
+
+	t.Run("YAML snippet", func(t *testing.T) {
+		yamlPath := writeTestFile(t, tempDir, "invalid.yaml", `---
+version: 1.0.0
+name: my-package
+description: A sample package
+`)
+
+		err := LintWithOptions(yamlPath, schemaPath, LintOptions{ShowSource: true})
+		if err == nil {
+			t.Fatalf("LintWithOptions() did not return an error for invalid order")
+		}
+
+		expected := yamlPath + `: properties out of order: 'version' should come after 'name' according to the schema
+  1 | ---
+> 2 | version: 1.0.0
+    | ^
+  3 | name: my-package`
+		if err.Error() != expected {
+			t.Errorf("LintWithOptions() returned unexpected snippet:\n%v\nexpected:\n%v", err, expected)
+		}
+	})
+
+	t.Run("JSON snippet", func(t *testing.T) {
+		jsonPath := writeTestFile(t, tempDir, "invalid.json", `{
+  "name": "my-package",
+  "description": "A sample package",
+  "version": "1.0.0"
+}`)
+
+		err := LintWithOptions(jsonPath, schemaPath, LintOptions{ShowSource: true})
+		if err == nil {
+			t.Fatalf("LintWithOptions() did not return an error for invalid order")
+		}
+
+		var violation *Violation
+		if !errors.As(err, &violation) {
+			t.Fatalf("LintWithOptions() did not return a *Violation: %v", err)
+		}
+		if violation.Line != 3 || violation.Column != 3 {
+			t.Errorf("Violation has incorrect position: got %d:%d, expected 3:3", violation.Line, violation.Column)
+		}
+		if !strings.Contains(violation.Snippet, `> 3 |   "description"`) || !strings.Contains(violation.Snippet, "  |   ^") {
+			t.Errorf("Violation has unexpected snippet:\n%s", violation.Snippet)
+		}
+	})
+
+	t.Run("No snippet by default", func(t *testing.T) {
+		yamlPath := writeTestFile(t, tempDir, "default.yaml", `---
+version: 1.0.0
+name: my-package
+`)
+
+		err := Lint(yamlPath, schemaPath)
+		if err == nil {
+			t.Fatalf("Lint() did not return an error for invalid order")
+		}
+		if strings.Contains(err.Error(), "\n") {
+			t.Errorf("Lint() returned a snippet without ShowSource: %v", err)
+		}
+	})
+}
+
+func TestSourceSnippet(t *testing.T) {
+	content := []byte("first: 1\n\tsecond: 2\n")
+
+	expected := `  1 | first: 1
+> 2 | 	second: 2
+    | 	^`
+	snippet := sourceSnippet(content, 2, 2)
+	if snippet != expected {
+		t.Errorf("sourceSnippet() returned:\n%s\nexpected:\n%s", snippet, expected)
+	}
+
+	if snippet := sourceSnippet(content, 10, 1); snippet != "" {
+		t.Errorf("sourceSnippet() returned a snippet for a line outside the content: %q", snippet)
+	}
+}
@@ -0,0 +1,31 @@
+package order
+
+import "gopkg.in/yaml.v3"
+
+// WalkDocument calls fn for every key of the mappings in node, a parsed YAML or JSON document or any node inside it,
+// with the path of the mapping holding the key in the form of Violation.Path, such as ["spec", "ports[1]"].
+// Keys are visited depth-first in document order, each one before the keys nested in its value. Aliases aren't
+// followed, so anchored mappings are only visited where they are defined
+func WalkDocument(node *yaml.Node, fn func(path []string, key string, value *yaml.Node)) {
+	walkNode(node, nil, fn)
+}
+
+// walkNode walks node, found at path, for WalkDocument
+func walkNode(node *yaml.Node, path []string, fn func(path []string, key string, value *yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkNode(child, path, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			fn(append([]string(nil), path...), key, value)
+			walkNode(value, appendPath(path, key), fn)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			walkNode(item, indexPath(path, i), fn)
+		}
+	}
+}
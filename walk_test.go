@@ -0,0 +1,48 @@
+package order
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWalkDocument(t *testing.T) {
+	var root yaml.Node
+	content := `name: web
+defaults: &defaults
+  retries: 3
+spec:
+  ports:
+    - name: http
+      port: 80
+    - port: 443
+  settings: *defaults
+status: {}
+`
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		t.Fatalf("yaml.Unmarshal() returned an error: %v", err)
+	}
+
+	var visited []string
+	WalkDocument(&root, func(path []string, key string, value *yaml.Node) {
+		visited = append(visited, strings.Join(append(path, key), ".")+"="+value.ShortTag())
+	})
+
+	expected := []string{
+		"name=!!str",
+		"defaults=!!map",
+		"defaults.retries=!!int",
+		"spec=!!map",
+		"spec.ports=!!seq",
+		"spec.ports[0].name=!!str",
+		"spec.ports[0].port=!!int",
+		"spec.ports[1].port=!!int",
+		"spec.settings=!!map",
+		"status=!!map",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("WalkDocument() visited %v, expected %v", visited, expected)
+	}
+}